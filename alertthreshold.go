@@ -0,0 +1,129 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Seuils d'alerte par type d'erreur (ALERT_THRESHOLDS, ex. "connection_refused=2,dns=3") :
+// une panne de ce type n'est signalée (Slack, webhook) qu'après autant d'échecs consécutifs
+// du même type. Sans seuil, un type d'erreur alerte dès la première transition.
+//
+// Ces seuils ne concernent que les alertes de transition : l'e-mail garde son propre seuil
+// d'échecs consécutifs (EMAIL_ALERT_THRESHOLD, tous types confondus) et l'escalade compte
+// toujours la durée de panne depuis le premier échec.
+var (
+	alertThresholds map[string]int
+
+	// deferredAlerts garde les transitions vers une panne retenues en attendant leur seuil
+	deferredAlerts = make(map[string]deferredAlert)
+	deferredMutex  sync.Mutex
+)
+
+// deferredAlert est une transition retenue, avec le type d'erreur en cours et sa série
+type deferredAlert struct {
+	transition Transition
+	kind       string
+	streak     int
+}
+
+// errorTypes liste les valeurs possibles de SiteStatus.ErrorType
+var errorTypes = []string{errorTypeDNS, errorTypeTimeout, errorTypeConnectionRefused, errorTypeTLS, errorTypeHTTPStatus, errorTypeOther}
+
+// loadAlertThresholdConfig lit ALERT_THRESHOLDS ; une valeur invalide arrête le démarrage
+func loadAlertThresholdConfig() {
+	v := os.Getenv("ALERT_THRESHOLDS")
+	if v == "" {
+		return
+	}
+	thresholds, err := parseAlertThresholds(v)
+	if err != nil {
+		logFatal("❌ ALERT_THRESHOLDS invalide (%q) : %v", v, err)
+	}
+	alertThresholds = thresholds
+	logInfo("🎚️ Seuils d'alerte par type d'erreur : %s", v)
+}
+
+// parseAlertThresholds lit une liste type=seuil séparée par des virgules
+func parseAlertThresholds(v string) (map[string]int, error) {
+	thresholds := make(map[string]int)
+	for _, part := range strings.Split(v, ",") {
+		kind, n, ok := strings.Cut(strings.TrimSpace(part), "=")
+		kind = strings.TrimSpace(kind)
+		if !ok {
+			return nil, fmt.Errorf("%q : type=seuil attendu", part)
+		}
+		if !slices.Contains(errorTypes, kind) {
+			return nil, fmt.Errorf("type d'erreur %q inconnu (valeurs possibles : %s)", kind, strings.Join(errorTypes, ", "))
+		}
+		threshold, err := strconv.Atoi(strings.TrimSpace(n))
+		if err != nil || threshold < 1 {
+			return nil, fmt.Errorf("seuil %q invalide pour %s (entier strictement positif attendu)", n, kind)
+		}
+		thresholds[kind] = threshold
+	}
+	return thresholds, nil
+}
+
+// alertThreshold renvoie le nombre d'échecs consécutifs d'un type avant alerte (1 par défaut)
+func alertThreshold(kind string) int {
+	if n, ok := alertThresholds[kind]; ok {
+		return n
+	}
+	return 1
+}
+
+// gateAlert applique les seuils par type d'erreur à une vérification. Une transition vers une
+// panne sous son seuil est retenue ; les vérifications suivantes la libèrent une fois le seuil
+// atteint, ou l'annulent si le site se rétablit avant (aucune alerte n'a alors été envoyée).
+// Renvoie la transition à notifier, s'il y en a une.
+func gateAlert(c statusChange, t Transition, isTransition bool) (Transition, bool) {
+	id := c.Current.Site.ID
+	deferredMutex.Lock()
+	defer deferredMutex.Unlock()
+
+	d, pending := deferredAlerts[id]
+	switch {
+	case pending && c.Current.IsUp:
+		delete(deferredAlerts, id)
+		// Un état différent de celui d'avant la panne (ex. dégradé) reste à signaler
+		if c.Current.State != d.transition.PreviousState {
+			d.transition.Status, d.transition.At = c.Current, c.Current.LastChecked
+			return d.transition, true
+		}
+		logEvent("info", siteFields(c.Current.Site), "🎚️ %s rétabli avant le seuil d'alerte (%s %d/%d) : aucune alerte",
+			c.Current.Site.Name, d.kind, d.streak, alertThreshold(d.kind))
+		return Transition{}, false
+	case pending:
+		if c.Current.ErrorType == d.kind {
+			d.streak++
+		} else {
+			d.kind, d.streak = c.Current.ErrorType, 1
+		}
+		d.transition.Status, d.transition.At = c.Current, c.Current.LastChecked
+		if d.streak >= alertThreshold(d.kind) {
+			delete(deferredAlerts, id)
+			return d.transition, true
+		}
+		deferredAlerts[id] = d
+		logEvent("info", siteFields(c.Current.Site), "🎚️ Alerte différée pour %s : %s (%d/%d)", c.Current.Site.Name, d.kind, d.streak, alertThreshold(d.kind))
+		return Transition{}, false
+	case isTransition && !c.Current.IsUp && alertThreshold(c.Current.ErrorType) > 1:
+		d = deferredAlert{transition: t, kind: c.Current.ErrorType, streak: 1}
+		deferredAlerts[id] = d
+		logEvent("info", siteFields(c.Current.Site), "🎚️ Alerte différée pour %s : %s (%d/%d)", c.Current.Site.Name, d.kind, d.streak, alertThreshold(d.kind))
+		return Transition{}, false
+	}
+	return t, isTransition
+}
+
+// forgetDeferredAlert oublie l'alerte retenue d'un site supprimé
+func forgetDeferredAlert(id string) {
+	deferredMutex.Lock()
+	delete(deferredAlerts, id)
+	deferredMutex.Unlock()
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseAlertThresholds(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    map[string]int
+		wantErr bool
+	}{
+		{"connection_refused=2", map[string]int{"connection_refused": 2}, false},
+		{" dns = 3 , timeout=1", map[string]int{"dns": 3, "timeout": 1}, false},
+		{"connection_refused", nil, true},
+		{"refused=2", nil, true},
+		{"dns=0", nil, true},
+		{"dns=abc", nil, true},
+	}
+	for _, tt := range tests {
+		got, err := parseAlertThresholds(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseAlertThresholds(%q) : erreur %v, attendue : %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseAlertThresholds(%q) = %v, attendu %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestGateAlert(t *testing.T) {
+	old := alertThresholds
+	defer func() { alertThresholds = old }()
+	alertThresholds = map[string]int{errorTypeConnectionRefused: 2}
+
+	site := Site{ID: "gate", Name: "gate"}
+	up := SiteStatus{Site: site, IsUp: true, State: stateUp}
+	refused := SiteStatus{Site: site, State: stateDown, ErrorType: errorTypeConnectionRefused}
+	timeout := SiteStatus{Site: site, State: stateDown, ErrorType: errorTypeTimeout}
+	degraded := SiteStatus{Site: site, IsUp: true, State: stateDegraded}
+
+	// Chaque scénario enchaîne des vérifications et liste celles qui donnent une alerte
+	tests := []struct {
+		name   string
+		checks []SiteStatus
+		alerts []bool
+	}{
+		{"type sans seuil", []SiteStatus{up, timeout, up}, []bool{false, true, true}},
+		{"seuil atteint", []SiteStatus{up, refused, refused, refused, up}, []bool{false, false, true, false, true}},
+		{"rétabli avant le seuil", []SiteStatus{up, refused, up}, []bool{false, false, false}},
+		{"changement de type", []SiteStatus{up, refused, timeout}, []bool{false, false, true}},
+		{"rétabli en état dégradé", []SiteStatus{up, refused, degraded}, []bool{false, false, true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer forgetDeferredAlert(site.ID)
+			prev := tt.checks[0]
+			for i, cur := range tt.checks[1:] {
+				tr, isTransition := detectTransition(prev, cur)
+				_, alert := gateAlert(statusChange{Previous: prev, Current: cur}, tr, isTransition)
+				if alert != tt.alerts[i+1] {
+					t.Errorf("vérification %d (%s) : alerte %v, attendue %v", i+1, cur.State, alert, tt.alerts[i+1])
+				}
+				prev = cur
+			}
+		})
+	}
+}
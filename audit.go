@@ -0,0 +1,242 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"os"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Journal d'audit des modifications de la liste des sites via l'API, en ajout seul :
+// dans le fichier AUDIT_LOG (une ligne JSON par entrée) ou, à défaut, dans la base DB_PATH.
+// Les écritures passent par un canal et une goroutine dédiée pour ne pas ralentir les requêtes.
+
+// Actions journalisées
+const (
+	auditCreate        = "create"
+	auditUpdate        = "update"
+	auditDelete        = "delete"
+	auditPause         = "pause"
+	auditResume        = "resume"
+	auditConfigReplace = "config_replace"
+)
+
+const (
+	defaultAuditLimit = 100
+	maxAuditLimit     = 1000
+)
+
+// AuditEntry est une modification journalisée
+type AuditEntry struct {
+	At     time.Time `json:"at"`
+	Action string    `json:"action"`
+	SiteID string    `json:"site_id"`
+	Detail string    `json:"detail,omitempty"` // pour config_replace : added, changed ou removed
+	Client string    `json:"client"`           // adresse du client à l'origine de la modification
+}
+
+var (
+	auditPath       string
+	auditFile       *os.File
+	auditToDB       bool
+	auditTrustProxy bool
+	auditQueue      chan AuditEntry
+	auditDone       sync.WaitGroup
+
+	// auditClosed est protégé par auditMutex : une requête encore en cours après
+	// l'arrêt du serveur ne doit pas écrire dans le canal fermé
+	auditMutex  sync.RWMutex
+	auditClosed bool
+)
+
+// openAuditLog active le journal d'audit si AUDIT_LOG ou la base d'historique est configuré.
+// À appeler après openHistoryDB.
+func openAuditLog() error {
+	auditPath = os.Getenv("AUDIT_LOG")
+	switch {
+	case auditPath != "":
+		f, err := os.OpenFile(auditPath, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+		if err != nil {
+			return err
+		}
+		auditFile = f
+		logInfo("📝 Journal d'audit : %s", auditPath)
+	case historyDB != nil:
+		_, err := historyDB.Exec(`
+			CREATE TABLE IF NOT EXISTS audit_log (
+				id      INTEGER PRIMARY KEY AUTOINCREMENT,
+				at      INTEGER NOT NULL,
+				action  TEXT    NOT NULL,
+				site_id TEXT    NOT NULL,
+				detail  TEXT    NOT NULL,
+				client  TEXT    NOT NULL
+			);
+		`)
+		if err != nil {
+			return err
+		}
+		auditToDB = true
+		logInfo("📝 Journal d'audit dans la base d'historique")
+	default:
+		return nil
+	}
+
+	auditTrustProxy = envBool("RATE_LIMIT_TRUST_PROXY", false)
+	auditQueue = make(chan AuditEntry, 256)
+	auditDone.Add(1)
+	go func() {
+		defer auditDone.Done()
+		for e := range auditQueue {
+			writeAuditEntry(e)
+		}
+	}()
+	return nil
+}
+
+// closeAuditLog écrit les entrées en attente puis ferme le journal (avant la base d'historique)
+func closeAuditLog() {
+	if auditQueue == nil {
+		return
+	}
+	auditMutex.Lock()
+	auditClosed = true
+	close(auditQueue)
+	auditMutex.Unlock()
+	auditDone.Wait()
+	if auditFile != nil {
+		auditFile.Sync()
+		auditFile.Close()
+	}
+}
+
+// recordAudit met une modification en file d'écriture ; sans journal configuré, ne fait rien
+func recordAudit(r *http.Request, action, siteID, detail string) {
+	if auditQueue == nil {
+		return
+	}
+	auditMutex.RLock()
+	defer auditMutex.RUnlock()
+	if auditClosed {
+		logWarn("⚠️ Entrée d'audit perdue, journal déjà fermé (%s %s)", action, siteID)
+		return
+	}
+	auditQueue <- AuditEntry{
+		At:     time.Now().UTC(),
+		Action: action,
+		SiteID: siteID,
+		Detail: detail,
+		Client: requestClientIP(r, auditTrustProxy),
+	}
+}
+
+// recordConfigReplaceAudit journalise chaque site concerné par un remplacement de la configuration
+func recordConfigReplaceAudit(r *http.Request, c siteChanges) {
+	for _, group := range []struct {
+		detail string
+		ids    []string
+	}{{"added", c.added}, {"changed", c.changed}, {"removed", c.removed}} {
+		for _, id := range group.ids {
+			recordAudit(r, auditConfigReplace, id, group.detail)
+		}
+	}
+}
+
+// writeAuditEntry ajoute une entrée au fichier ou à la base ; un échec est journalisé
+func writeAuditEntry(e AuditEntry) {
+	var err error
+	if auditFile != nil {
+		line, _ := json.Marshal(e)
+		_, err = auditFile.Write(append(line, '\n'))
+	} else if auditToDB {
+		_, err = historyDB.Exec(`INSERT INTO audit_log (at, action, site_id, detail, client) VALUES (?, ?, ?, ?, ?)`,
+			e.At.UnixMilli(), e.Action, e.SiteID, e.Detail, e.Client)
+	}
+	if err != nil {
+		logError("❌ Entrée d'audit non écrite (%s %s) : %v", e.Action, e.SiteID, err)
+	}
+}
+
+// readAuditEntries renvoie les limit dernières entrées, de la plus récente à la plus ancienne
+func readAuditEntries(limit int) ([]AuditEntry, error) {
+	if auditToDB {
+		return queryAuditEntries(limit)
+	}
+
+	f, err := os.Open(auditPath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []AuditEntry
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var e AuditEntry
+		// Une ligne en cours d'écriture ou abîmée est ignorée
+		if json.Unmarshal(scanner.Bytes(), &e) == nil {
+			entries = append(entries, e)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	entries = entries[max(len(entries)-limit, 0):]
+	slices.Reverse(entries)
+	return entries, nil
+}
+
+// queryAuditEntries lit les dernières entrées d'audit dans la base
+func queryAuditEntries(limit int) ([]AuditEntry, error) {
+	rows, err := historyDB.Query(`SELECT at, action, site_id, detail, client FROM audit_log ORDER BY id DESC LIMIT ?`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []AuditEntry
+	for rows.Next() {
+		var e AuditEntry
+		var at int64
+		if err := rows.Scan(&at, &e.Action, &e.SiteID, &e.Detail, &e.Client); err != nil {
+			return nil, err
+		}
+		e.At = time.UnixMilli(at).UTC()
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+// handleAudit renvoie les dernières modifications journalisées (?limit=, 100 par défaut)
+func handleAudit(w http.ResponseWriter, r *http.Request) {
+	if auditQueue == nil {
+		http.Error(w, "Journal d'audit non configuré (AUDIT_LOG ou DB_PATH)", http.StatusNotFound)
+		return
+	}
+	limit := defaultAuditLimit
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			http.Error(w, "Paramètre limit invalide", http.StatusBadRequest)
+			return
+		}
+		limit = min(n, maxAuditLimit)
+	}
+
+	entries, err := readAuditEntries(limit)
+	if err != nil {
+		logWarn("⚠️ Lecture du journal d'audit impossible : %v", err)
+		http.Error(w, "Journal d'audit indisponible", http.StatusInternalServerError)
+		return
+	}
+	if entries == nil {
+		entries = []AuditEntry{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(w, r, entries)
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// authExempt liste les chemins accessibles sans clé : les sondes des load balancers et la page
+// du tableau de bord, qui ne contient aucune donnée et envoie elle-même la clé à /api/status
+var authExempt = map[string]bool{
+	"/":                 true,
+	"/dashboard":        true,
+	"/api/health":       true,
+	"/api/health/live":  true,
+	"/api/health/ready": true,
+	"/api/ping":         true,
+}
+
+// authExemptPrefix couvre les badges, intégrés comme images dans des pages qui ne peuvent pas envoyer de clé
+const authExemptPrefix = "/api/badge/"
+
+// authMiddleware exige la clé API_KEY dans X-API-Key ou Authorization: Bearer.
+// Sans API_KEY, l'API reste ouverte comme auparavant. Pour /api/diagnose, dont le
+// Bearer porte DIAGNOSE_TOKEN, la clé passe par X-API-Key.
+func authMiddleware(next http.Handler) http.Handler {
+	key := os.Getenv("API_KEY")
+	if key == "" {
+		return next
+	}
+	logInfo("🔒 Authentification par clé d'API activée")
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if authExempt[r.URL.Path] || strings.HasPrefix(r.URL.Path, authExemptPrefix) || validAPIKey(r, key) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		w.Header().Set("WWW-Authenticate", `Bearer realm="site-monitor"`)
+		http.Error(w, "Clé d'API manquante ou invalide", http.StatusUnauthorized)
+	})
+}
+
+// validAPIKey compare en temps constant les clés présentées à la clé attendue
+func validAPIKey(r *http.Request, key string) bool {
+	candidates := []string{r.Header.Get("X-API-Key")}
+	if bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		candidates = append(candidates, bearer)
+	}
+	return matchesAPIKey(key, candidates...)
+}
+
+// matchesAPIKey indique, en temps constant pour chaque candidate, si l'une d'elles est la clé attendue
+func matchesAPIKey(key string, candidates ...string) bool {
+	for _, c := range candidates {
+		if c != "" && subtle.ConstantTimeCompare([]byte(c), []byte(key)) == 1 {
+			return true
+		}
+	}
+	return false
+}
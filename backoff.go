@@ -0,0 +1,63 @@
+package main
+
+import "time"
+
+// Ralentissement des sites en échec prolongé : inutile de solliciter toutes les minutes
+// un site en panne depuis une heure. Après backoffAfter échecs d'affilée, l'intervalle
+// double à chaque nouvel échec, jusqu'à backoffMax ; il revient à la normale dès que le site répond.
+var (
+	backoffAfter int
+	backoffMax   time.Duration
+)
+
+// loadBackoffConfig lit BACKOFF_AFTER_FAILURES (3 par défaut, 0 désactive)
+// et BACKOFF_MAX_SECONDS (600 par défaut)
+func loadBackoffConfig() {
+	backoffAfter = envInt("BACKOFF_AFTER_FAILURES", 3)
+	backoffMax = time.Duration(envInt("BACKOFF_MAX_SECONDS", 600)) * time.Second
+	if backoffAfter > 0 {
+		logInfo("🐢 Cadence ralentie après %d échec(s) d'affilée, jusqu'à %s", backoffAfter, backoffMax)
+	}
+}
+
+// backoffInterval renvoie l'intervalle allongé d'un site après failures échecs d'affilée,
+// ou 0 s'il n'est pas ralenti
+func backoffInterval(base time.Duration, failures int) time.Duration {
+	if backoffAfter == 0 || failures < backoffAfter || backoffMax <= base {
+		return 0
+	}
+	// Plafonner l'exposant évite un débordement ; le plafond backoffMax est atteint bien avant
+	shift := min(failures-backoffAfter+1, 30)
+	return min(base<<shift, backoffMax)
+}
+
+// scheduleInterval renvoie la cadence appliquée à un site, ralentissement compris
+func scheduleInterval(st SiteStatus) time.Duration {
+	base := effectiveInterval(st.Site)
+	if d := backoffInterval(base, st.ConsecutiveFailures); d > 0 {
+		return d
+	}
+	return base
+}
+
+// intervalTable associe à chaque site sa cadence courante
+type intervalTable map[string]time.Duration
+
+// siteIntervals calcule la cadence courante de chaque site à partir des statuts
+func siteIntervals() intervalTable {
+	statusMutex.RLock()
+	defer statusMutex.RUnlock()
+	t := make(intervalTable, len(statuses))
+	for _, st := range statuses {
+		t[st.Site.ID] = scheduleInterval(st)
+	}
+	return t
+}
+
+// of renvoie la cadence d'un site, l'intervalle normal s'il n'a pas encore de statut
+func (t intervalTable) of(s Site) time.Duration {
+	if d, ok := t[s.ID]; ok {
+		return d
+	}
+	return effectiveInterval(s)
+}
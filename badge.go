@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fmt"
+	"html"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+)
+
+// Couleurs des badges, reprises de shields.io
+const (
+	badgeGreen  = "#4c1"
+	badgeYellow = "#dfb317"
+	badgeRed    = "#e05d44"
+	badgeGray   = "#9f9f9f"
+)
+
+// badgeTemplate est un badge plat à deux parties : libellé à gauche, état à droite
+const badgeTemplate = `<svg xmlns="http://www.w3.org/2000/svg" width="%[1]d" height="20" role="img" aria-label="%[3]s: %[4]s">
+<title>%[3]s: %[4]s</title>
+<linearGradient id="s" x2="0" y2="100%%"><stop offset="0" stop-color="#bbb" stop-opacity=".1"/><stop offset="1" stop-opacity=".1"/></linearGradient>
+<clipPath id="r"><rect width="%[1]d" height="20" rx="3" fill="#fff"/></clipPath>
+<g clip-path="url(#r)"><rect width="%[2]d" height="20" fill="#555"/><rect x="%[2]d" width="%[6]d" height="20" fill="%[5]s"/><rect width="%[1]d" height="20" fill="url(#s)"/></g>
+<g fill="#fff" text-anchor="middle" font-family="Verdana,Geneva,DejaVu Sans,sans-serif" font-size="11">
+<text x="%[7]d" y="15" fill="#010101" fill-opacity=".3">%[3]s</text><text x="%[7]d" y="14">%[3]s</text>
+<text x="%[8]d" y="15" fill="#010101" fill-opacity=".3">%[4]s</text><text x="%[8]d" y="14">%[4]s</text>
+</g>
+</svg>
+`
+
+// handleBadge renvoie un badge SVG de l'état d'un site, à intégrer dans un README.
+// Un ID inconnu donne un badge gris « unknown » plutôt qu'un 404, pour ne pas casser l'image.
+func handleBadge(w http.ResponseWriter, r *http.Request) {
+	id := strings.TrimSuffix(r.PathValue("file"), ".svg")
+
+	label, message, color := id, "unknown", badgeGray
+	statusMutex.RLock()
+	for _, st := range statuses {
+		if st.Site.ID != id {
+			continue
+		}
+		label = st.Site.Name
+		switch {
+		case st.State == statePending, st.State == statePaused:
+			message = st.State
+		case st.State == stateDegraded:
+			message, color = "degraded", badgeYellow
+		case st.IsUp:
+			message, color = "up", badgeGreen
+		default:
+			message, color = "down", badgeRed
+		}
+		break
+	}
+	statusMutex.RUnlock()
+
+	w.Header().Set("Content-Type", "image/svg+xml; charset=utf-8")
+	setStatusCacheHeaders(w)
+	w.Write(renderBadge(label, message, color))
+}
+
+// renderBadge dessine le badge ; la largeur des textes est estimée à ~7px par caractère
+func renderBadge(label, message, color string) []byte {
+	labelWidth := utf8.RuneCountInString(label)*7 + 10
+	messageWidth := utf8.RuneCountInString(message)*7 + 10
+	return fmt.Appendf(nil, badgeTemplate,
+		labelWidth+messageWidth,
+		labelWidth,
+		html.EscapeString(label),
+		html.EscapeString(message),
+		color,
+		messageWidth,
+		labelWidth/2,
+		labelWidth+messageWidth/2,
+	)
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// secret est une chaîne lue normalement dans la configuration mais jamais renvoyée en clair
+type secret string
+
+// MarshalJSON masque la valeur ; un secret vide reste vide
+func (s secret) MarshalJSON() ([]byte, error) {
+	if s == "" {
+		return json.Marshal("")
+	}
+	return json.Marshal(redactedSecret)
+}
+
+// hasBasicAuth indique si le site définit des identifiants Basic complets
+func hasBasicAuth(site Site) bool {
+	return site.Username != "" && site.Password != ""
+}
+
+// applyBasicAuth ajoute l'en-tête Authorization Basic du site, s'il en définit un
+func applyBasicAuth(req *http.Request, site Site) {
+	if hasBasicAuth(site) {
+		req.SetBasicAuth(site.Username, string(site.Password))
+	}
+}
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+)
+
+// maxBodyRead borne la lecture du corps pour qu'une réponse énorme ne sature pas la mémoire
+var maxBodyRead int64 = defaultMaxBodyRead
+
+const defaultMaxBodyRead = 64 << 10
+
+// loadBodyConfig lit MAX_BODY_BYTES (64 Ko par défaut)
+func loadBodyConfig() {
+	if n := envInt("MAX_BODY_BYTES", defaultMaxBodyRead); n > 0 {
+		maxBodyRead = int64(n)
+	}
+}
+
+// readBoundedBody lit au plus maxBodyRead octets et indique si le corps dépassait cette limite
+func readBoundedBody(body io.Reader) (data []byte, truncated bool, err error) {
+	// Un octet de plus que la limite permet de savoir si le corps a été tronqué
+	data, err = io.ReadAll(io.LimitReader(body, maxBodyRead+1))
+	if int64(len(data)) > maxBodyRead {
+		return data[:maxBodyRead], true, err
+	}
+	return data, false, err
+}
+
+// needsBody indique si les options du site imposent de lire le corps de la réponse
+func needsBody(site Site) bool {
+	return site.RequireNonEmptyBody || hasKeywords(site) || site.MustNotContain != "" ||
+		site.SchemaFile != "" || site.WatchContent
+}
+
+// hasKeywords indique si le site attend des chaînes dans le corps (ExpectKeywords ou MustContain)
+func hasKeywords(site Site) bool {
+	return len(site.ExpectKeywords) > 0 || site.MustContain != ""
+}
+
+// inspectBody lit le corps (dans la limite de maxBodyRead) une seule fois et applique
+// les assertions de contenu du site. La première assertion en échec passe le site en panne.
+func inspectBody(status *SiteStatus, site Site, body io.Reader, contentLength int64) {
+	// Sans schéma à valider, texte interdit ni empreinte (qui imposent de tout lire),
+	// les mots-clés peuvent être cherchés au fil du flux
+	if hasKeywords(site) && site.SchemaFile == "" && site.MustNotContain == "" && !site.WatchContent {
+		if status.IsUp {
+			streamKeywords(status, site, body)
+		}
+		return
+	}
+
+	data, truncated, err := readBoundedBody(body)
+	status.BodyTruncated = truncated
+	length := int64(len(data))
+	if contentLength > length {
+		length = contentLength
+	}
+	status.BodyLength = &length
+
+	if err != nil {
+		status.IsUp = false
+		status.Error = fmt.Sprintf("lecture du corps impossible : %v", err)
+		status.ErrorType = classifyError(err)
+		return
+	}
+	if !status.IsUp {
+		return
+	}
+	// Empreinte d'une réponse acceptée uniquement : une page d'erreur ne doit pas passer pour un changement
+	if site.WatchContent {
+		status.ContentHash = contentHash(data)
+	}
+
+	if site.RequireNonEmptyBody && length == 0 {
+		status.IsUp = false
+		status.Error = "corps de réponse vide"
+		return
+	}
+	if msg := checkKeywords(site, data); msg != "" {
+		status.IsUp = false
+		status.Error = msg
+		return
+	}
+	if site.SchemaFile != "" {
+		if msg := checkSchema(site.SchemaFile, data, truncated); msg != "" {
+			status.IsUp = false
+			status.Error = msg
+		}
+	}
+}
+
+// checkKeywords renvoie un message d'erreur si le texte interdit est présent
+// ou si les mots-clés attendus ne sont pas satisfaits
+func checkKeywords(site Site, data []byte) string {
+	if site.MustNotContain != "" && bytes.Contains(data, []byte(site.MustNotContain)) {
+		return fmt.Sprintf("texte interdit présent dans le corps : %q", site.MustNotContain)
+	}
+	if site.MustContain != "" && !bytes.Contains(data, []byte(site.MustContain)) {
+		return mustContainError(site)
+	}
+	if len(site.ExpectKeywords) == 0 {
+		return ""
+	}
+	missing := removeFound(site.ExpectKeywords, data)
+	if keywordsSatisfied(site, missing) {
+		return ""
+	}
+	return keywordError(site, missing)
+}
+
+// streamKeywords lit le corps par blocs et s'arrête dès que la condition sur les mots-clés
+// est remplie, sans attendre la fin d'une réponse lente ou volumineuse. Une fenêtre glissante
+// conserve la fin du bloc précédent pour trouver un mot-clé à cheval sur deux lectures.
+// BytesRead indique ensuite combien d'octets ont été nécessaires, BodyLength aussi en cas d'arrêt anticipé.
+func streamKeywords(status *SiteStatus, site Site, body io.Reader) {
+	missing := site.ExpectKeywords
+	mustFound := site.MustContain == ""
+	overlap := len(site.MustContain)
+	for _, kw := range missing {
+		if len(kw) > overlap {
+			overlap = len(kw)
+		}
+	}
+	overlap = max(overlap-1, 0)
+
+	buf := make([]byte, 8<<10)
+	var window []byte
+	var total int64
+
+	for total < maxBodyRead {
+		n, err := body.Read(buf[:min(int64(len(buf)), maxBodyRead-total)])
+		if n > 0 {
+			total += int64(n)
+			window = append(window, buf[:n]...)
+			missing = removeFound(missing, window)
+			if !mustFound {
+				mustFound = bytes.Contains(window, []byte(site.MustContain))
+			}
+			if mustFound && keywordsSatisfied(site, missing) {
+				// Lecture interrompue : la longueur est celle des octets lus jusque-là
+				status.BodyLength = &total
+				return
+			}
+			if len(window) > overlap {
+				window = append(window[:0], window[len(window)-overlap:]...)
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			status.IsUp = false
+			status.Error = fmt.Sprintf("lecture du corps impossible : %v", err)
+			status.ErrorType = classifyError(err)
+			return
+		}
+	}
+
+	if total < maxBodyRead {
+		status.BodyLength = &total
+	} else {
+		status.BodyTruncated = true
+	}
+	status.IsUp = false
+	if site.RequireNonEmptyBody && total == 0 {
+		status.Error = "corps de réponse vide"
+		return
+	}
+	if !mustFound {
+		status.Error = mustContainError(site)
+		return
+	}
+	status.Error = keywordError(site, missing)
+}
+
+// removeFound renvoie les mots-clés absents de data (sans modifier keywords)
+func removeFound(keywords []string, data []byte) []string {
+	var missing []string
+	for _, kw := range keywords {
+		if !bytes.Contains(data, []byte(kw)) {
+			missing = append(missing, kw)
+		}
+	}
+	return missing
+}
+
+// keywordsSatisfied applique le mode all/any à la liste des mots-clés encore absents
+func keywordsSatisfied(site Site, missing []string) bool {
+	if len(site.ExpectKeywords) == 0 {
+		return true
+	}
+	if site.KeywordMode == "any" {
+		return len(missing) < len(site.ExpectKeywords)
+	}
+	return len(missing) == 0
+}
+
+// keywordError décrit les mots-clés manquants selon le mode
+func keywordError(site Site, missing []string) string {
+	if site.KeywordMode == "any" {
+		return fmt.Sprintf("aucun des mots-clés attendus trouvé : %q", missing)
+	}
+	return fmt.Sprintf("mot(s)-clé(s) absent(s) : %q", missing)
+}
+
+// mustContainError décrit l'absence du texte obligatoire
+func mustContainError(site Site) string {
+	return fmt.Sprintf("texte attendu absent du corps : %q", site.MustContain)
+}
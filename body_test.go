@@ -0,0 +1,32 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestStreamKeywordsBodyLength(t *testing.T) {
+	long := strings.Repeat("x", 20<<10) + "fin"
+	tests := []struct {
+		name    string
+		site    Site
+		body    string
+		wantUp  bool
+		wantLen int64
+	}{
+		{"mot-clé trouvé", Site{MustContain: "ok"}, "status ok", true, 9},
+		{"arrêt au premier bloc", Site{MustContain: "x"}, long, true, 8 << 10},
+		{"mot-clé absent", Site{MustContain: "ok"}, "status ko", false, 9},
+		{"mot-clé en fin de corps", Site{ExpectKeywords: []string{"fin"}}, long, true, int64(len(long))},
+	}
+	for _, tt := range tests {
+		status := SiteStatus{IsUp: true}
+		streamKeywords(&status, tt.site, strings.NewReader(tt.body))
+		if status.IsUp != tt.wantUp {
+			t.Errorf("%s : up %v, attendu %v (%s)", tt.name, status.IsUp, tt.wantUp, status.Error)
+		}
+		if status.BodyLength == nil || *status.BodyLength != tt.wantLen {
+			t.Errorf("%s : body_length %v, attendu %d", tt.name, status.BodyLength, tt.wantLen)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package main
+
+import "sync"
+
+// Abonnés notifiés après chaque passe de vérification (flux gRPC WatchStatus, etc.)
+var (
+	subscribers      = make(map[chan []SiteStatus]struct{})
+	subscribersMutex sync.Mutex
+)
+
+// subscribeStatuses enregistre un abonné ; la fonction renvoyée le désinscrit.
+// Le canal a une capacité de 1 : un abonné lent ne reçoit que le dernier instantané.
+func subscribeStatuses() (<-chan []SiteStatus, func()) {
+	ch := make(chan []SiteStatus, 1)
+	subscribersMutex.Lock()
+	subscribers[ch] = struct{}{}
+	subscribersMutex.Unlock()
+
+	return ch, func() {
+		subscribersMutex.Lock()
+		delete(subscribers, ch)
+		subscribersMutex.Unlock()
+	}
+}
+
+// publishStatuses diffuse un instantané des statuts sans jamais bloquer la passe
+func publishStatuses(snapshot []SiteStatus) {
+	subscribersMutex.Lock()
+	defer subscribersMutex.Unlock()
+
+	for ch := range subscribers {
+		select {
+		case <-ch: // remplace l'instantané non encore consommé
+		default:
+		}
+		select {
+		case ch <- snapshot:
+		default:
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+)
+
+// Résultat de l'auto-test de démarrage. Écrit une seule fois avant le lancement
+// du serveur HTTP, puis seulement lu : aucun verrou n'est nécessaire.
+var (
+	canaryFailed   bool
+	canaryRequired bool
+)
+
+// runStartupCanary vérifie que le moniteur atteint STARTUP_CANARY_URL avant de se déclarer prêt,
+// pour distinguer « le moniteur n'atteint rien » de « les sites sont en panne ».
+// Avec STARTUP_CANARY_REQUIRED=true, un échec rend /api/health indisponible (503).
+func runStartupCanary() {
+	url := os.Getenv("STARTUP_CANARY_URL")
+	if url == "" {
+		return
+	}
+	canaryRequired = envBool("STARTUP_CANARY_REQUIRED", false)
+
+	client := &http.Client{Timeout: 10 * time.Second, Transport: sharedTransport}
+	resp, err := client.Get(url)
+	if err == nil {
+		resp.Body.Close()
+		if resp.StatusCode >= 500 {
+			err = fmt.Errorf("code HTTP %d", resp.StatusCode)
+		}
+	}
+	if err != nil {
+		canaryFailed = true
+		logError("🚨🚨🚨 AUTO-TEST DE DÉMARRAGE EN ÉCHEC 🚨🚨🚨")
+		logError("🚨 Impossible de joindre %s : %v", url, err)
+		logError("🚨 Le réseau du moniteur est probablement en cause : les sites risquent tous d'apparaître en panne")
+		return
+	}
+	logInfo("✅ Auto-test de démarrage réussi (%s)", url)
+}
+
+// canaryBlocksReadiness indique si l'échec de l'auto-test doit rendre le service indisponible
+func canaryBlocksReadiness() bool {
+	return canaryFailed && canaryRequired
+}
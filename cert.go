@@ -0,0 +1,66 @@
+package main
+
+import (
+	"crypto/tls"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+// Surveillance de l'expiration des certificats TLS
+var (
+	certWarnDays    int  // seuil d'alerte en jours (CERT_WARN_DAYS, 14 par défaut)
+	certAlertsSlack bool // CERT_EXPIRY_ALERTS : relaie aussi l'avertissement sur Slack
+
+	// certWarned retient les sites déjà signalés, pour n'avertir qu'au franchissement du seuil
+	certWarned = make(map[string]bool)
+	certMutex  sync.Mutex
+)
+
+// loadCertConfig lit la configuration de l'avertissement d'expiration
+func loadCertConfig() {
+	certWarnDays = envInt("CERT_WARN_DAYS", 14)
+	certAlertsSlack = envBool("CERT_EXPIRY_ALERTS", false)
+}
+
+// recordCertExpiry renseigne l'expiration la plus proche de la chaîne présentée par le serveur
+// (un intermédiaire qui expire casse le site autant que le certificat final)
+func recordCertExpiry(status *SiteStatus, state *tls.ConnectionState) {
+	if state == nil || len(state.PeerCertificates) == 0 {
+		return
+	}
+	expiry := state.PeerCertificates[0].NotAfter
+	for _, cert := range state.PeerCertificates[1:] {
+		if cert.NotAfter.Before(expiry) {
+			expiry = cert.NotAfter
+		}
+	}
+	days := int(math.Floor(time.Until(expiry).Hours() / 24))
+	status.CertExpiry = &expiry
+	status.CertDaysLeft = &days
+}
+
+// checkCertExpiry avertit quand un certificat passe sous le seuil, une seule fois
+// jusqu'à ce qu'il soit renouvelé
+func checkCertExpiry(st SiteStatus) {
+	if st.CertDaysLeft == nil {
+		return
+	}
+	days := *st.CertDaysLeft
+
+	certMutex.Lock()
+	warned := certWarned[st.Site.ID]
+	below := days < certWarnDays
+	certWarned[st.Site.ID] = below
+	certMutex.Unlock()
+
+	if !below || warned {
+		return
+	}
+	msg := fmt.Sprintf("🔐 Le certificat TLS de %s expire dans %d jour(s) (%s)", st.Site.Name, days, st.CertExpiry.Format("2006-01-02"))
+	logEvent("warn", siteFields(st.Site), "%s", msg)
+	if certAlertsSlack && slackWebhookURL != "" {
+		goNotify("slack", func() { sendSlack(fmt.Sprintf("%s\n%s", msg, redactURLPassword(st.Site.URL))) })
+	}
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"net/http"
+	"time"
+)
+
+// checkWriteTimeout couvre une passe complète, plus longue que le WriteTimeout du serveur
+// lorsque des sites lents épuisent leurs nouvelles tentatives
+const checkWriteTimeout = 2 * time.Minute
+
+// checkPassWait borne l'attente d'une passe déjà en cours avant de renvoyer les derniers statuts
+const checkPassWait = 2 * time.Second
+
+// CheckAllResult est la réponse de POST /api/check. Cached indique que les statuts viennent
+// de la dernière passe terminée (une autre passe tournait encore), AgeSeconds leur ancienneté.
+type CheckAllResult struct {
+	Cached     bool         `json:"cached"`
+	AgeSeconds int          `json:"age_seconds"`
+	Statuses   []SiteStatus `json:"statuses"`
+}
+
+// handleCheckAll lance immédiatement une passe sur tous les sites, sans attendre le prochain tick,
+// et renvoie les statuts obtenus. passMutex sérialise cette passe avec celles du planificateur :
+// si une passe est déjà en cours au-delà de checkPassWait, les derniers statuts sont renvoyés.
+func handleCheckAll(w http.ResponseWriter, r *http.Request) {
+	http.NewResponseController(w).SetWriteDeadline(time.Now().Add(checkWriteTimeout))
+
+	logInfo("🔁 Vérification manuelle de tous les sites")
+	result := CheckAllResult{}
+	checked, fresh := tryCheckSites(r.Context(), currentSites(), checkPassWait)
+	if fresh {
+		result.Statuses = checked
+	} else {
+		logInfo("🔁 Passe déjà en cours : derniers statuts renvoyés")
+		// statuses est remplacé (copie sur écriture), jamais modifié en place
+		statusMutex.RLock()
+		result.Statuses = statuses
+		statusMutex.RUnlock()
+		result.Cached = true
+		result.AgeSeconds = int(lastPassAge().Seconds())
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(w, r, result)
+}
+
+// handleCheckSite revérifie un seul site et renvoie son nouveau statut ; seule son entrée
+// de statuses est remplacée. Un site en pause est refusé (409).
+func handleCheckSite(w http.ResponseWriter, r *http.Request) {
+	statusMutex.RLock()
+	site, ok := findSite(r.PathValue("id"))
+	statusMutex.RUnlock()
+	if !ok {
+		http.Error(w, "Site introuvable", http.StatusNotFound)
+		return
+	}
+	// Une passe ignore les sites en pause : le résultat serait vide
+	if site.Paused {
+		http.Error(w, "Site en pause : reprenez-le avant de le vérifier", http.StatusConflict)
+		return
+	}
+	http.NewResponseController(w).SetWriteDeadline(time.Now().Add(checkWriteTimeout))
+
+	logEvent("info", siteFields(site), "🔁 Vérification manuelle de %s", site.Name)
+	var result SiteStatus
+	for _, st := range checkSites(r.Context(), []Site{site}, false) {
+		if st.Site.ID == site.ID {
+			result = st
+		}
+	}
+	// Site supprimé pendant la vérification
+	if result.Site.ID == "" {
+		http.Error(w, "Site introuvable", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(w, r, result)
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// Import et export de la configuration complète, pour migrer un environnement en une requête.
+// Comme pour les autres modifications via l'API, rien n'est écrit dans le fichier de configuration :
+// un rechargement de celui-ci remplace la liste importée.
+
+// handleGetConfig renvoie la liste effective des sites, ajouts et modifications via l'API compris
+func handleGetConfig(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(w, r, currentSites())
+}
+
+// handlePutConfig remplace toute la liste des sites. Elle est validée en entier d'abord :
+// au moindre problème, rien n'est appliqué. Les sites dont l'id existe déjà gardent leur statut.
+func handlePutConfig(w http.ResponseWriter, r *http.Request) {
+	var list []Site
+	if err := json.NewDecoder(r.Body).Decode(&list); err != nil {
+		http.Error(w, "JSON invalide (tableau de sites attendu)", http.StatusBadRequest)
+		return
+	}
+	if list == nil {
+		list = []Site{}
+	}
+	for i := range list {
+		if list[i].Name == "" {
+			list[i].Name = list[i].ID
+		}
+	}
+	// Restauration des secrets masqués et remplacement sous le même verrou : une modification
+	// concurrente ne peut pas s'intercaler et voir ses secrets écrasés par des valeurs périmées
+	statusMutex.Lock()
+	for i := range list {
+		current, exists := findSite(list[i].ID)
+		list[i] = keepRedactedSecrets(list[i], current, exists)
+	}
+	if err := validateSites(list); err != nil {
+		statusMutex.Unlock()
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	changes := replaceSites(list, true)
+	statusMutex.Unlock()
+	finishReplaceSites(list, changes, true)
+
+	recordConfigReplaceAudit(r, changes)
+
+	w.Header().Set("Content-Type", "application/json")
+	writeJSON(w, r, list)
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// contentHash renvoie l'empreinte SHA-256 (hexadécimale) du corps lu
+func contentHash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// trackContentChange compare l'empreinte du corps à la dernière empreinte connue. Une vérification
+// en échec (panne, code refusé, assertion sur le corps) reprend celle d'avant : un changement survenu pendant
+// une panne est ainsi signalé au rétablissement. La première empreinte n'est pas un changement.
+func trackContentChange(prev SiteStatus, r *SiteStatus) {
+	if !r.Site.WatchContent {
+		return
+	}
+	if r.ContentHash == "" || !r.IsUp {
+		r.ContentHash = prev.ContentHash
+		return
+	}
+	if prev.ContentHash == "" || prev.ContentHash == r.ContentHash {
+		return
+	}
+	r.ContentChanged = true
+	logEvent("warn", siteFields(r.Site), "📝 Contenu de %s modifié (empreinte %.12s → %.12s)", r.Site.Name, prev.ContentHash, r.ContentHash)
+}
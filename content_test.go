@@ -0,0 +1,61 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestContentHash(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"", "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"},
+		{"hello", "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"},
+	}
+	for _, tt := range tests {
+		if got := contentHash([]byte(tt.in)); got != tt.want {
+			t.Errorf("contentHash(%q) = %s, attendu %s", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestTrackContentChange(t *testing.T) {
+	watched := Site{ID: "w", Name: "w", WatchContent: true}
+	hashA, hashB := contentHash([]byte("version A")), contentHash([]byte("version B"))
+
+	// Chaque scénario enchaîne les empreintes des vérifications ("" : panne ou code refusé,
+	// préfixe "!" : corps lu mais vérification en échec)
+	tests := []struct {
+		name        string
+		site        Site
+		hashes      []string
+		wantChanged []bool
+		wantHash    []string
+	}{
+		{"contenu stable", watched, []string{hashA, hashA}, []bool{false, false}, []string{hashA, hashA}},
+		{"contenu modifié", watched, []string{hashA, hashB, hashB}, []bool{false, true, false}, []string{hashA, hashB, hashB}},
+		{"modifié pendant une panne", watched, []string{hashA, "", "", hashB}, []bool{false, false, false, true}, []string{hashA, hashA, hashA, hashB}},
+		{"rétabli sans changement", watched, []string{hashA, "", hashA}, []bool{false, false, false}, []string{hashA, hashA, hashA}},
+		{"première empreinte après des pannes", watched, []string{"", "", hashA}, []bool{false, false, false}, []string{"", "", hashA}},
+		{"corps refusé par une assertion", watched, []string{hashA, "!" + hashB, hashA}, []bool{false, false, false}, []string{hashA, hashA, hashA}},
+		{"suivi désactivé", Site{ID: "n"}, []string{hashA, hashB}, []bool{false, false}, []string{hashA, hashB}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var prev SiteStatus
+			for i, h := range tt.hashes {
+				failed := strings.HasPrefix(h, "!")
+				cur := SiteStatus{Site: tt.site, ContentHash: strings.TrimPrefix(h, "!"), IsUp: h != "" && !failed}
+				trackContentChange(prev, &cur)
+				if cur.ContentChanged != tt.wantChanged[i] {
+					t.Errorf("vérification %d : ContentChanged = %v, attendu %v", i, cur.ContentChanged, tt.wantChanged[i])
+				}
+				if cur.ContentHash != tt.wantHash[i] {
+					t.Errorf("vérification %d : ContentHash = %.12s, attendu %.12s", i, cur.ContentHash, tt.wantHash[i])
+				}
+				prev = cur
+			}
+		})
+	}
+}
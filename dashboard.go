@@ -0,0 +1,24 @@
+package main
+
+import (
+	"embed"
+	"html/template"
+	"net/http"
+)
+
+//go:embed web/dashboard.html
+var webFS embed.FS
+
+// dashboardTemplate est la page de tableau de bord, autonome (aucune dépendance JS externe)
+var dashboardTemplate = template.Must(template.ParseFS(webFS, "web/dashboard.html"))
+
+// handleDashboard sert la page HTML qui affiche /api/status et se rafraîchit à chaque intervalle global
+func handleDashboard(w http.ResponseWriter, r *http.Request) {
+	data := struct{ RefreshSeconds int }{RefreshSeconds: max(int(currentInterval().Seconds()), minIntervalSeconds)}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	if err := dashboardTemplate.Execute(w, data); err != nil {
+		logError("❌ Rendu du tableau de bord impossible : %v", err)
+	}
+}
@@ -0,0 +1,226 @@
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	"crypto/tls"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptrace"
+	"os"
+	"strings"
+	"time"
+)
+
+// diagnoseSnippetBytes borne l'extrait de corps renvoyé par le diagnostic
+const diagnoseSnippetBytes = 2048
+
+// Diagnosis est le résultat détaillé d'une vérification ponctuelle (équivalent de curl -v)
+type Diagnosis struct {
+	Site            Site                `json:"site"`
+	Type            string              `json:"type"`
+	Method          string              `json:"method,omitempty"`
+	URL             string              `json:"url,omitempty"`
+	StartedAt       time.Time           `json:"started_at"`
+	Timings         DiagnosisTimings    `json:"timings"`
+	StatusCode      int                 `json:"status_code,omitempty"`
+	Protocol        string              `json:"protocol,omitempty"`
+	RemoteAddr      string              `json:"remote_addr,omitempty"`
+	FinalURL        string              `json:"final_url,omitempty"`
+	Redirects       []RedirectHop       `json:"redirects,omitempty"`
+	ResponseHeaders map[string][]string `json:"response_headers,omitempty"`
+	TLS             *TLSDiagnosis       `json:"tls,omitempty"`
+	BodySnippet     string              `json:"body_snippet,omitempty"`
+	Error           string              `json:"error,omitempty"`
+
+	// Endpoints diagnostic de chaque URL d'un site multi-endpoints
+	Endpoints []Diagnosis `json:"endpoints,omitempty"`
+
+	// Result vérification tcp ou grpc telle que le monitoring l'effectue
+	Result *SiteStatus `json:"result,omitempty"`
+}
+
+// DiagnosisTimings décompose la durée de la requête (en millisecondes)
+type DiagnosisTimings struct {
+	DNSMs     int64 `json:"dns_ms"`
+	ConnectMs int64 `json:"connect_ms"`
+	TLSMs     int64 `json:"tls_ms"`
+	TTFBMs    int64 `json:"ttfb_ms"`
+	TotalMs   int64 `json:"total_ms"`
+}
+
+// RedirectHop décrit une redirection suivie pendant le diagnostic
+type RedirectHop struct {
+	URL        string `json:"url"`
+	StatusCode int    `json:"status_code"`
+	Location   string `json:"location"`
+}
+
+// TLSDiagnosis résume la session TLS et la chaîne de certificats présentée
+type TLSDiagnosis struct {
+	Version            string                `json:"version"`
+	CipherSuite        string                `json:"cipher_suite"`
+	ServerName         string                `json:"server_name"`
+	NegotiatedProtocol string                `json:"negotiated_protocol,omitempty"`
+	Certificates       []CertificateOverview `json:"certificates"`
+}
+
+// CertificateOverview reprend les champs utiles d'un certificat
+type CertificateOverview struct {
+	Subject   string    `json:"subject"`
+	Issuer    string    `json:"issuer"`
+	DNSNames  []string  `json:"dns_names,omitempty"`
+	NotBefore time.Time `json:"not_before"`
+	NotAfter  time.Time `json:"not_after"`
+}
+
+// handleDiagnose lance un diagnostic détaillé d'un site sans modifier les statuts stockés.
+// L'endpoint n'est actif que si DIAGNOSE_TOKEN est défini, et exige ce jeton.
+func handleDiagnose(w http.ResponseWriter, r *http.Request) {
+	token := os.Getenv("DIAGNOSE_TOKEN")
+	if token == "" {
+		http.Error(w, "Diagnostic désactivé (DIAGNOSE_TOKEN non défini)", http.StatusForbidden)
+		return
+	}
+	given := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+		http.Error(w, "Jeton de diagnostic invalide", http.StatusUnauthorized)
+		return
+	}
+
+	statusMutex.RLock()
+	site, ok := findSite(r.PathValue("id"))
+	statusMutex.RUnlock()
+	if !ok {
+		http.Error(w, "Site introuvable", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(w, r, diagnoseSite(site))
+}
+
+// diagnoseSite reproduit la vérification du monitoring (même type, requête, transport et
+// politique de redirection) en collectant tous les détails
+func diagnoseSite(site Site) Diagnosis {
+	switch {
+	case len(site.Endpoints) > 0:
+		d := Diagnosis{Site: site, Type: checkTypeHTTP, StartedAt: time.Now()}
+		for _, endpoint := range site.Endpoints {
+			probe := site
+			probe.URL = endpoint
+			probe.Endpoints = nil
+			d.Endpoints = append(d.Endpoints, diagnoseHTTP(probe))
+		}
+		d.Timings.TotalMs = time.Since(d.StartedAt).Milliseconds()
+		return d
+	case checkType(site) == checkTypeHTTP:
+		return diagnoseHTTP(site)
+	default:
+		return diagnoseCheck(site)
+	}
+}
+
+// diagnoseCheck lance la vérification tcp ou grpc du site ; ces protocoles n'ont ni en-têtes
+// ni corps à détailler, le résultat complet de la vérification est renvoyé
+func diagnoseCheck(site Site) Diagnosis {
+	d := Diagnosis{Site: site, Type: checkType(site), URL: redactURLPassword(site.URL), StartedAt: time.Now()}
+	status := checkers[checkType(site)](site)
+	d.Timings.TotalMs = time.Since(d.StartedAt).Milliseconds()
+	d.StatusCode = status.StatusCode
+	d.RemoteAddr = status.RemoteAddr
+	d.Error = status.Error
+	d.Result = &status
+	return d
+}
+
+// diagnoseHTTP effectue la requête de vérification du site, instrumentée
+func diagnoseHTTP(site Site) Diagnosis {
+	d := Diagnosis{Site: site, Type: checkTypeHTTP, Method: checkMethod(site), URL: redactURLPassword(site.URL), StartedAt: time.Now()}
+
+	var dnsStart, connectStart, tlsStart time.Time
+	trace := &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) { dnsStart = time.Now() },
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			d.Timings.DNSMs = time.Since(dnsStart).Milliseconds()
+		},
+		ConnectStart: func(string, string) { connectStart = time.Now() },
+		ConnectDone: func(string, string, error) {
+			d.Timings.ConnectMs = time.Since(connectStart).Milliseconds()
+		},
+		TLSHandshakeStart: func() { tlsStart = time.Now() },
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			d.Timings.TLSMs = time.Since(tlsStart).Milliseconds()
+		},
+		GotConn: func(info httptrace.GotConnInfo) {
+			d.RemoteAddr = info.Conn.RemoteAddr().String()
+		},
+		GotFirstResponseByte: func() {
+			d.Timings.TTFBMs = time.Since(d.StartedAt).Milliseconds()
+		},
+	}
+
+	client := &http.Client{
+		Timeout:   siteTimeout(site),
+		Transport: transportFor(site),
+		// Même politique que checkHTTP : redirection non suivie renvoyée telle quelle
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if site.FlagRedirects || !followRedirects(site) {
+				return http.ErrUseLastResponse
+			}
+			d.Redirects = append(d.Redirects, RedirectHop{
+				URL:        redactURLPassword(via[len(via)-1].URL.String()),
+				StatusCode: req.Response.StatusCode,
+				Location:   redactURLPassword(req.URL.String()),
+			})
+			if len(via) >= 10 {
+				return errors.New("arrêt après 10 redirections")
+			}
+			return nil
+		},
+	}
+
+	ctx := httptrace.WithClientTrace(context.Background(), trace)
+	req, err := newCheckRequest(ctx, site)
+	if err != nil {
+		d.Error = err.Error()
+		return d
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		d.Timings.TotalMs = time.Since(d.StartedAt).Milliseconds()
+		d.Error = err.Error()
+		return d
+	}
+	defer resp.Body.Close()
+
+	snippet, _ := io.ReadAll(io.LimitReader(resp.Body, diagnoseSnippetBytes))
+	d.Timings.TotalMs = time.Since(d.StartedAt).Milliseconds()
+	d.StatusCode = resp.StatusCode
+	d.Protocol = resp.Proto
+	d.FinalURL = redactURLPassword(resp.Request.URL.String())
+	d.ResponseHeaders = resp.Header
+	d.BodySnippet = string(snippet)
+
+	if cs := resp.TLS; cs != nil {
+		t := &TLSDiagnosis{
+			Version:            tls.VersionName(cs.Version),
+			CipherSuite:        tls.CipherSuiteName(cs.CipherSuite),
+			ServerName:         cs.ServerName,
+			NegotiatedProtocol: cs.NegotiatedProtocol,
+		}
+		for _, cert := range cs.PeerCertificates {
+			t.Certificates = append(t.Certificates, CertificateOverview{
+				Subject:   cert.Subject.String(),
+				Issuer:    cert.Issuer.String(),
+				DNSNames:  cert.DNSNames,
+				NotBefore: cert.NotBefore,
+				NotAfter:  cert.NotAfter,
+			})
+		}
+		d.TLS = t
+	}
+	return d
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"crypto/tls"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"os"
+	"strings"
+	"time"
+)
+
+// Configuration des alertes e-mail (SMTP_HOST et ALERT_TO requis pour les activer)
+var (
+	smtpHost       string
+	smtpPort       string
+	smtpUser       string
+	smtpPass       string
+	smtpFrom       string
+	alertTo        []string
+	emailThreshold int
+)
+
+// emailEvent décrit un e-mail à envoyer : panne prolongée ou rétablissement
+type emailEvent struct {
+	Status    SiteStatus
+	Recovered bool
+	Failures  int // échecs consécutifs au moment de la panne
+}
+
+// loadEmailConfig lit la configuration SMTP depuis l'environnement
+func loadEmailConfig() {
+	smtpHost = os.Getenv("SMTP_HOST")
+	smtpPort = envString("SMTP_PORT", "587")
+	smtpUser = os.Getenv("SMTP_USER")
+	smtpPass = os.Getenv("SMTP_PASS")
+	smtpFrom = envString("SMTP_FROM", smtpUser)
+	emailThreshold = envInt("EMAIL_ALERT_THRESHOLD", 3)
+	if emailThreshold < 1 {
+		emailThreshold = 1
+	}
+
+	alertTo = nil
+	for _, addr := range strings.Split(os.Getenv("ALERT_TO"), ",") {
+		if addr = strings.TrimSpace(addr); addr != "" {
+			alertTo = append(alertTo, addr)
+		}
+	}
+
+	if emailEnabled() {
+		logInfo("🔔 Alertes e-mail activées (%d destinataire(s), après %d échec(s) consécutif(s))", len(alertTo), emailThreshold)
+	}
+}
+
+func emailEnabled() bool {
+	return smtpHost != "" && len(alertTo) > 0
+}
+
+// emailEventFor décide si un changement de statut doit déclencher un e-mail :
+// à l'instant où le seuil d'échecs consécutifs est atteint, puis au rétablissement
+// d'un site pour lequel une alerte a été envoyée.
+func emailEventFor(c statusChange) (emailEvent, bool) {
+	if !emailEnabled() {
+		return emailEvent{}, false
+	}
+	if !c.Current.IsUp && c.Current.ConsecutiveFailures == emailThreshold {
+		return emailEvent{Status: c.Current, Failures: c.Current.ConsecutiveFailures}, true
+	}
+	if c.Current.IsUp && c.Previous.ConsecutiveFailures >= emailThreshold {
+		return emailEvent{Status: c.Current, Recovered: true, Failures: c.Previous.ConsecutiveFailures}, true
+	}
+	return emailEvent{}, false
+}
+
+// sendEmail envoie l'alerte par SMTP (STARTTLS si le serveur le propose)
+func sendEmail(ev emailEvent) {
+	subject, body := formatEmail(ev)
+	if err := deliverEmail(subject, body); err != nil {
+		logEvent("warn", siteFields(ev.Status.Site), "⚠️ E-mail d'alerte non envoyé pour %s : %v", ev.Status.Site.Name, err)
+	}
+}
+
+// formatEmail construit l'objet et le corps d'une alerte
+func formatEmail(ev emailEvent) (string, string) {
+	st := ev.Status
+	var b strings.Builder
+	var subject string
+	if ev.Recovered {
+		subject = fmt.Sprintf("[Site Monitor] %s est de nouveau UP", st.Site.Name)
+		fmt.Fprintf(&b, "%s répond de nouveau après %d vérification(s) en échec.\n\n", st.Site.Name, ev.Failures)
+	} else {
+		subject = fmt.Sprintf("[Site Monitor] %s est DOWN", st.Site.Name)
+		fmt.Fprintf(&b, "%s est en panne depuis %d vérification(s) consécutive(s).\n\n", st.Site.Name, ev.Failures)
+	}
+	fmt.Fprintf(&b, "URL : %s\nCode : %d\nVérifié à : %s\n", redactURLPassword(st.Site.URL), st.StatusCode, st.LastChecked.Format(time.RFC3339))
+	if st.Error != "" {
+		fmt.Fprintf(&b, "Erreur : %s\n", st.Error)
+	}
+	if st.Site.Description != "" {
+		fmt.Fprintf(&b, "Description : %s\n", st.Site.Description)
+	}
+	if st.Site.Runbook != "" {
+		fmt.Fprintf(&b, "Runbook : %s\n", st.Site.Runbook)
+	}
+	return subject, b.String()
+}
+
+// deliverEmail ouvre une session SMTP bornée dans le temps et envoie le message
+func deliverEmail(subject, body string) error {
+	addr := net.JoinHostPort(smtpHost, smtpPort)
+	conn, err := net.DialTimeout("tcp", addr, 10*time.Second)
+	if err != nil {
+		return err
+	}
+	conn.SetDeadline(time.Now().Add(30 * time.Second))
+
+	c, err := smtp.NewClient(conn, smtpHost)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer c.Close()
+
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err := c.StartTLS(&tls.Config{ServerName: smtpHost}); err != nil {
+			return err
+		}
+	}
+	if smtpUser != "" {
+		if err := c.Auth(smtp.PlainAuth("", smtpUser, smtpPass, smtpHost)); err != nil {
+			return err
+		}
+	}
+	if err := c.Mail(smtpFrom); err != nil {
+		return err
+	}
+	for _, to := range alertTo {
+		if err := c.Rcpt(to); err != nil {
+			return err
+		}
+	}
+
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	msg := fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\nDate: %s\r\nMIME-Version: 1.0\r\nContent-Type: text/plain; charset=UTF-8\r\n\r\n%s",
+		smtpFrom,
+		strings.Join(alertTo, ", "),
+		mime.QEncoding.Encode("utf-8", subject),
+		time.Now().Format(time.RFC1123Z),
+		strings.ReplaceAll(body, "\n", "\r\n"),
+	)
+	if _, err := w.Write([]byte(msg)); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// EndpointStatus est le résultat d'une des URL d'un site multi-endpoints
+type EndpointStatus struct {
+	URL          string `json:"url"`
+	IsUp         bool   `json:"is_up"`
+	State        string `json:"state"`
+	StatusCode   int    `json:"status_code"`
+	ResponseTime int64  `json:"response_time_ms"`
+	Error        string `json:"error,omitempty"`
+	ErrorType    string `json:"error_type,omitempty"`
+}
+
+// validateEndpoints vérifie les URL d'un site multi-endpoints (http uniquement)
+func validateEndpoints(s Site) []string {
+	if len(s.Endpoints) == 0 {
+		return nil
+	}
+	if checkType(s) != checkTypeHTTP {
+		return []string{"endpoints ne s'applique qu'aux sites http"}
+	}
+	var problems []string
+	for _, raw := range s.Endpoints {
+		if u, err := url.Parse(raw); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			problems = append(problems, fmt.Sprintf("endpoint %q invalide (http ou https attendu)", raw))
+		}
+	}
+	return problems
+}
+
+// checkEndpoints vérifie chaque URL d'un site avec ses options (timeout, assertions, en-têtes...).
+// Les sondes sont successives : le site n'occupe qu'une place de MAX_CONCURRENCY.
+// Le site est up si toutes le sont ; le statut global reprend le premier échec (ou la première
+// sonde si tout va bien) et le temps de réponse le plus long.
+func checkEndpoints(site Site) SiteStatus {
+	results := make([]SiteStatus, len(site.Endpoints))
+	var slowest SiteStatus
+	failed := -1
+	for i, endpoint := range site.Endpoints {
+		probe := site
+		probe.URL = endpoint
+		probe.Endpoints = nil
+		results[i] = checkHTTP(probe)
+		if results[i].ResponseTime > slowest.ResponseTime || i == 0 {
+			slowest = results[i]
+		}
+		if !results[i].IsUp && failed < 0 {
+			failed = i
+		}
+	}
+
+	status := results[0]
+	var failures []string
+	if failed >= 0 {
+		status = results[failed]
+		for _, r := range results {
+			if !r.IsUp {
+				failures = append(failures, fmt.Sprintf("%s : %s", redactURLPassword(r.Site.URL), endpointError(r)))
+			}
+		}
+		status.Error = strings.Join(failures, " ; ")
+	}
+	status.Site = site
+	status.ResponseTime = slowest.ResponseTime
+	status.ResponseTimeUs = slowest.ResponseTimeUs
+	status.Endpoints = make([]EndpointStatus, len(results))
+	for i, r := range results {
+		if !r.IsUp && r.ErrorType == "" {
+			r.ErrorType = errorTypeOther
+		}
+		status.Endpoints[i] = EndpointStatus{
+			URL:          redactURLPassword(r.Site.URL),
+			IsUp:         r.IsUp,
+			State:        r.State,
+			StatusCode:   r.StatusCode,
+			ResponseTime: r.ResponseTime,
+			Error:        r.Error,
+			ErrorType:    r.ErrorType,
+		}
+	}
+	return status
+}
+
+// endpointError décrit l'échec d'une sonde, y compris sans message (code refusé)
+func endpointError(r SiteStatus) string {
+	if r.Error != "" {
+		return r.Error
+	}
+	return fmt.Sprintf("code HTTP %d", r.StatusCode)
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"os"
+	"strconv"
+)
+
+// envString renvoie la valeur de la variable d'environnement key, ou def si elle est vide
+func envString(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return def
+}
+
+// envInt lit un entier positif ou nul depuis l'environnement ; une valeur invalide est ignorée
+func envInt(key string, def int) int {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 0 {
+		logWarn("⚠️ Valeur invalide pour %s (%q), utilisation de la valeur par défaut %d", key, v, def)
+		return def
+	}
+	return n
+}
+
+// envFloat lit un nombre décimal positif ou nul depuis l'environnement
+func envFloat(key string, def float64) float64 {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil || f < 0 {
+		logWarn("⚠️ Valeur invalide pour %s (%q), utilisation de la valeur par défaut %g", key, v, def)
+		return def
+	}
+	return f
+}
+
+// envBool lit un booléen (true/false, 1/0...) depuis l'environnement
+func envBool(key string, def bool) bool {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		logWarn("⚠️ Valeur invalide pour %s (%q), utilisation de la valeur par défaut %t", key, v, def)
+		return def
+	}
+	return b
+}
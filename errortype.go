@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net"
+	"syscall"
+)
+
+// Catégories d'échec de SiteStatus.ErrorType, pour compter les pannes par cause
+const (
+	errorTypeDNS               = "dns"
+	errorTypeTimeout           = "timeout"
+	errorTypeConnectionRefused = "connection_refused"
+	errorTypeTLS               = "tls"
+	errorTypeHTTPStatus        = "http_status"
+	errorTypeOther             = "other"
+)
+
+// classifyError range une erreur de connexion ou de requête dans une catégorie.
+// Une résolution DNS expirée reste une erreur DNS : c'est la cause la plus utile.
+func classifyError(err error) string {
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return errorTypeDNS
+	}
+	if isTLSError(err) {
+		return errorTypeTLS
+	}
+	if errors.Is(err, syscall.ECONNREFUSED) {
+		return errorTypeConnectionRefused
+	}
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return errorTypeTimeout
+	}
+	return errorTypeOther
+}
+
+// isTLSError reconnaît les échecs de négociation TLS et de validation du certificat
+func isTLSError(err error) bool {
+	var (
+		verifyErr    *tls.CertificateVerificationError
+		recordErr    tls.RecordHeaderError
+		alertErr     tls.AlertError
+		unknownCA    x509.UnknownAuthorityError
+		invalidCert  x509.CertificateInvalidError
+		hostnameErr  x509.HostnameError
+		systemRootEr x509.SystemRootsError
+	)
+	return errors.As(err, &verifyErr) || errors.As(err, &recordErr) || errors.As(err, &alertErr) ||
+		errors.As(err, &unknownCA) || errors.As(err, &invalidCert) || errors.As(err, &hostnameErr) ||
+		errors.As(err, &systemRootEr)
+}
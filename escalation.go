@@ -0,0 +1,198 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Escalade : un site en panne sans interruption au-delà de chaque seuil d'ESCALATION_MINUTES
+// (ex. "5,15,60") donne lieu à une nouvelle alerte, en plus de celle de la transition.
+// Une fin d'escalade est signalée au rétablissement.
+var (
+	escalationThresholds []time.Duration
+
+	// escalations suit les sites en panne : début de la panne et nombre de seuils déjà signalés
+	escalations     = make(map[string]*escalationState)
+	escalationMutex sync.Mutex
+)
+
+type escalationState struct {
+	downSince time.Time
+	level     int
+}
+
+// loadEscalationConfig lit ESCALATION_MINUTES (minutes strictement croissantes, séparées par
+// des virgules) ; vide par défaut : aucune escalade
+func loadEscalationConfig() {
+	v := os.Getenv("ESCALATION_MINUTES")
+	if v == "" {
+		return
+	}
+	var thresholds []time.Duration
+	for _, part := range strings.Split(v, ",") {
+		n, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil || n <= 0 || (len(thresholds) > 0 && time.Duration(n)*time.Minute <= thresholds[len(thresholds)-1]) {
+			logFatal("❌ ESCALATION_MINUTES invalide (%q) : minutes strictement positives et croissantes attendues", v)
+		}
+		thresholds = append(thresholds, time.Duration(n)*time.Minute)
+	}
+	escalationThresholds = thresholds
+	logInfo("📣 Escalade des pannes prolongées après %s", v)
+}
+
+// escalationEvent est une alerte d'escalade (ou sa levée) à diffuser
+type escalationEvent struct {
+	Status    SiteStatus
+	Level     int
+	DownSince time.Time
+	Recovered bool
+}
+
+// trackEscalations met à jour la durée de panne de chaque site et renvoie les alertes dues.
+// La maintenance gèle l'escalade ; une reprise après pause repart de zéro.
+func trackEscalations(changes []statusChange) []escalationEvent {
+	if len(escalationThresholds) == 0 {
+		return nil
+	}
+	escalationMutex.Lock()
+	defer escalationMutex.Unlock()
+
+	var events []escalationEvent
+	for _, c := range changes {
+		st := c.Current
+		id := st.Site.ID
+		if c.Previous.State == statePaused {
+			delete(escalations, id)
+		}
+		esc := escalations[id]
+		switch {
+		case st.IsUp:
+			if esc != nil && esc.level > 0 {
+				events = append(events, escalationEvent{Status: st, Level: esc.level, DownSince: esc.downSince, Recovered: true})
+			}
+			delete(escalations, id)
+		case st.Maintenance:
+		case esc == nil:
+			escalations[id] = &escalationState{downSince: st.LastChecked}
+		default:
+			// Un seul message si plusieurs seuils sont franchis d'un coup (cadence lente, backoff)
+			down := st.LastChecked.Sub(esc.downSince)
+			level := esc.level
+			for level < len(escalationThresholds) && down >= escalationThresholds[level] {
+				level++
+			}
+			if level > esc.level {
+				esc.level = level
+				events = append(events, escalationEvent{Status: st, Level: level, DownSince: esc.downSince})
+			}
+		}
+	}
+	return events
+}
+
+// forgetEscalation efface le suivi de panne d'un site (supprimé ou recréé)
+func forgetEscalation(id string) {
+	escalationMutex.Lock()
+	delete(escalations, id)
+	escalationMutex.Unlock()
+}
+
+// dispatchEscalations diffuse les alertes d'escalade sur les canaux configurés,
+// avec les mêmes suppressions que les alertes de transition (isolation, instabilité)
+func dispatchEscalations(events []escalationEvent, isolated bool) {
+	for _, ev := range events {
+		st := ev.Status
+		if isolated || st.Flapping || st.warmup {
+			logEvent("info", siteFields(st.Site), "🔕 Escalade supprimée pour %s", st.Site.Name)
+			continue
+		}
+		downFor := st.LastChecked.Sub(ev.DownSince).Round(time.Second)
+		if ev.Recovered {
+			logEvent("info", siteFields(st.Site), "📣 %s rétabli après %s de panne, fin de l'escalade", st.Site.Name, downFor)
+		} else {
+			logEvent("warn", siteFields(st.Site), "📣 %s toujours en panne depuis %s (escalade %d/%d)", st.Site.Name, downFor, ev.Level, len(escalationThresholds))
+		}
+
+		if slackWebhookURL != "" {
+			goNotify("slack", func() { sendSlack(formatSlackEscalation(ev, downFor)) })
+		}
+		if webhookURL != "" {
+			goNotify("webhook", func() { sendEscalationWebhook(ev, downFor) })
+		}
+		if emailEnabled() {
+			subject, body := formatEscalationEmail(ev, downFor)
+			goNotify("email", func() {
+				if err := deliverEmail(subject, body); err != nil {
+					logEvent("warn", siteFields(st.Site), "⚠️ E-mail d'escalade non envoyé pour %s : %v", st.Site.Name, err)
+				}
+			})
+		}
+	}
+}
+
+// formatSlackEscalation construit le message Slack d'une escalade
+func formatSlackEscalation(ev escalationEvent, downFor time.Duration) string {
+	st := ev.Status
+	if ev.Recovered {
+		return fmt.Sprintf("✅ *%s* est rétabli après %s de panne, fin de l'escalade", st.Site.Name, downFor)
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "🚨 *%s* est toujours DOWN depuis %s (escalade %d/%d)\n", st.Site.Name, downFor, ev.Level, len(escalationThresholds))
+	fmt.Fprintf(&b, "URL : %s\n", redactURLPassword(st.Site.URL))
+	if st.Error != "" {
+		fmt.Fprintf(&b, "Erreur : %s\n", st.Error)
+	}
+	if st.Site.Runbook != "" {
+		fmt.Fprintf(&b, "Runbook : %s\n", st.Site.Runbook)
+	}
+	return b.String()
+}
+
+// formatEscalationEmail construit l'objet et le corps d'un e-mail d'escalade
+func formatEscalationEmail(ev escalationEvent, downFor time.Duration) (string, string) {
+	st := ev.Status
+	if ev.Recovered {
+		return fmt.Sprintf("[Site Monitor] %s rétabli, fin de l'escalade", st.Site.Name),
+			fmt.Sprintf("%s répond de nouveau après %s de panne.\n", st.Site.Name, downFor)
+	}
+	subject := fmt.Sprintf("[Site Monitor] ESCALADE %d/%d : %s DOWN depuis %s", ev.Level, len(escalationThresholds), st.Site.Name, downFor)
+	body := fmt.Sprintf("%s est en panne sans interruption depuis %s (%s).\n\nURL : %s\nErreur : %s\n",
+		st.Site.Name, ev.DownSince.Format(time.RFC3339), downFor, redactURLPassword(st.Site.URL), st.Error)
+	return subject, body
+}
+
+// escalationPayload est le corps JSON envoyé à WEBHOOK_URL pour une escalade
+type escalationPayload struct {
+	SiteStatus
+	Event          string    `json:"event"` // escalation ou escalation_recovered
+	Level          int       `json:"escalation_level"`
+	DownSince      time.Time `json:"down_since"`
+	DownForSeconds int       `json:"down_for_seconds"`
+}
+
+// sendEscalationWebhook livre une escalade au webhook générique (best-effort, sans nouvelle tentative)
+func sendEscalationWebhook(ev escalationEvent, downFor time.Duration) {
+	event := "escalation"
+	if ev.Recovered {
+		event = "escalation_recovered"
+	}
+	payload, err := json.Marshal(escalationPayload{
+		SiteStatus:     ev.Status,
+		Event:          event,
+		Level:          ev.Level,
+		DownSince:      ev.DownSince.UTC(),
+		DownForSeconds: int(downFor.Seconds()),
+	})
+	if err != nil {
+		logWarn("⚠️ Webhook : encodage impossible : %v", err)
+		return
+	}
+	if err := postJSON(webhookClient, webhookURL, payload); err != nil {
+		logEvent("warn", siteFields(ev.Status.Site), "⚠️ Webhook d'escalade non délivré pour %s : %v", ev.Status.Site.Name, err)
+	}
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// exportWriteTimeout laisse le temps de transférer un historique volumineux
+const exportWriteTimeout = 5 * time.Minute
+
+// exportPageSize lignes lues en base à chaque requête de l'export
+const exportPageSize = 500
+
+// exportRecord ligne de check_results lue pour l'export
+type exportRecord struct {
+	SiteID string
+	checkRecord
+}
+
+// handleExportCSV diffuse l'historique conservé (la base si DB_PATH est défini, sinon la mémoire)
+// au format CSV, page par page sans tout charger en mémoire
+func handleExportCSV(w http.ResponseWriter, r *http.Request) {
+	statusMutex.RLock()
+	list := sites
+	statusMutex.RUnlock()
+	byID := make(map[string]Site, len(list))
+	for _, s := range list {
+		byID[s.ID] = s
+	}
+
+	rc := http.NewResponseController(w)
+	rc.SetWriteDeadline(time.Now().Add(exportWriteTimeout))
+
+	filename := fmt.Sprintf("site-monitor-history-%s.csv", time.Now().Format("20060102-150405"))
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"site_id", "name", "url", "is_up", "response_time_ms", "status_code", "last_checked"})
+	write := func(id string, at time.Time, isUp bool, responseTime int64, code int) error {
+		s := byID[id]
+		return cw.Write([]string{
+			id, s.Name, redactURLPassword(s.URL),
+			strconv.FormatBool(isUp),
+			strconv.FormatInt(responseTime, 10),
+			strconv.Itoa(code),
+			at.UTC().Format(time.RFC3339),
+		})
+	}
+
+	// Flush régulier pour que le client reçoive les lignes au fil de l'eau
+	rows := 0
+	flush := func() {
+		if rows++; rows%500 == 0 {
+			cw.Flush()
+			rc.Flush()
+		}
+	}
+
+	if historyDB != nil {
+		// Lecture par pages (pagination sur rowid) : la connexion unique de la base est rendue
+		// avant d'écrire chaque page, un client lent ne bloque donc jamais les passes
+		var after int64
+		for {
+			page, last, err := exportPage(r, after)
+			if err != nil {
+				if rows == 0 {
+					logError("❌ Export CSV impossible : %v", err)
+					http.Error(w, "Historique indisponible", http.StatusInternalServerError)
+				} else {
+					logError("❌ Export CSV interrompu : %v", err)
+				}
+				return
+			}
+			for _, rec := range page {
+				if write(rec.SiteID, rec.At, rec.IsUp, rec.ResponseTime, rec.StatusCode) != nil {
+					return
+				}
+				flush()
+			}
+			if len(page) < exportPageSize {
+				break
+			}
+			after = last
+		}
+	} else {
+		for _, s := range list {
+			for _, rec := range siteHistory(s.ID) {
+				if write(s.ID, rec.At, rec.IsUp, rec.ResponseTime, rec.StatusCode) != nil {
+					return
+				}
+				flush()
+			}
+		}
+	}
+	cw.Flush()
+}
+
+// exportPage lit jusqu'à exportPageSize résultats de rowid strictement supérieur à after
+// et renvoie le rowid du dernier ; les lignes sont fermées avant le retour
+func exportPage(r *http.Request, after int64) ([]exportRecord, int64, error) {
+	res, err := historyDB.QueryContext(r.Context(), `SELECT rowid, site_id, checked_at, is_up, response_time_ms, status_code
+		FROM check_results WHERE rowid > ? ORDER BY rowid LIMIT ?`, after, exportPageSize)
+	if err != nil {
+		return nil, after, err
+	}
+	defer res.Close()
+
+	page := make([]exportRecord, 0, exportPageSize)
+	last := after
+	for res.Next() {
+		var rec exportRecord
+		var checkedAt int64
+		if err := res.Scan(&last, &rec.SiteID, &checkedAt, &rec.IsUp, &rec.ResponseTime, &rec.StatusCode); err != nil {
+			return nil, after, err
+		}
+		rec.At = time.UnixMilli(checkedAt)
+		page = append(page, rec)
+	}
+	return page, last, res.Err()
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Options de ligne de commande (pratiques sous systemd) : chacune prime sur la variable
+// d'environnement correspondante, qui prime elle-même sur la valeur par défaut.
+// Une option fournie est recopiée dans l'environnement, si bien que le reste du code
+// n'a qu'une source à lire.
+var flagEnv = []struct {
+	name, env, usage string
+}{
+	{"port", "PORT", "port d'écoute de l'API (PORT, " + defaultPort + " par défaut)"},
+	{"config", "SITES_CONFIG", "fichier de configuration des sites (SITES_CONFIG)"},
+	{"interval", "CHECK_INTERVAL_SECONDS", "intervalle global de vérification en secondes (CHECK_INTERVAL_SECONDS, 60 par défaut)"},
+	{"concurrency", "MAX_CONCURRENCY", "nombre maximal de vérifications simultanées (MAX_CONCURRENCY, 20 par défaut)"},
+	{"log-format", "LOG_FORMAT", "format des logs : text ou json (LOG_FORMAT)"},
+}
+
+// flagSet retient les options effectivement passées, pour l'affichage de la configuration
+var flagSet = make(map[string]bool)
+
+// parseFlags lit la ligne de commande et reporte les options fournies dans l'environnement.
+// À appeler en tout premier : les réglages lus à l'initialisation du paquet sont recalculés.
+func parseFlags() {
+	values := make(map[string]*string, len(flagEnv))
+	for _, f := range flagEnv {
+		values[f.name] = flag.String(f.name, "", f.usage)
+	}
+	flag.Parse()
+
+	for _, f := range flagEnv {
+		if v := *values[f.name]; v != "" {
+			os.Setenv(f.env, v)
+			flagSet[f.env] = true
+		}
+	}
+	logJSON = os.Getenv("LOG_FORMAT") == "json"
+	sitesConfigPath = resolveSitesConfigPath()
+}
+
+// configSource indique d'où vient un réglage : option, env ou défaut
+func configSource(env string) string {
+	switch {
+	case flagSet[env]:
+		return "option"
+	case os.Getenv(env) != "":
+		return "env"
+	default:
+		return "défaut"
+	}
+}
+
+// logEffectiveConfig affiche les réglages principaux retenus et leur origine
+func logEffectiveConfig(port string) {
+	logFormat := "text"
+	if logJSON {
+		logFormat = "json"
+	}
+	values := map[string]string{
+		"PORT":                   port,
+		"SITES_CONFIG":           sitesConfigPath,
+		"CHECK_INTERVAL_SECONDS": currentInterval().String(),
+		"MAX_CONCURRENCY":        fmt.Sprint(maxConcurrency),
+		"LOG_FORMAT":             logFormat,
+	}
+	parts := make([]string, len(flagEnv))
+	for i, f := range flagEnv {
+		parts[i] = fmt.Sprintf("%s=%s (%s)", f.name, values[f.env], configSource(f.env))
+	}
+	logInfo("⚙️ Configuration effective : %s", strings.Join(parts, ", "))
+}
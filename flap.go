@@ -0,0 +1,74 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Détection des sites instables : un site qui change d'état plus de flapThreshold fois
+// sur ses flapWindow dernières vérifications est "flapping". Ses alertes de transition
+// sont alors remplacées par une seule alerte d'instabilité, jusqu'à ce qu'il se stabilise.
+var (
+	flapThreshold int
+	flapWindow    int
+
+	// flapStates garde les derniers états de chaque site
+	flapStates = make(map[string][]string)
+	flapMutex  sync.Mutex
+)
+
+// loadFlapConfig lit FLAP_THRESHOLD (4 par défaut, 0 désactive) et FLAP_WINDOW (10 vérifications)
+func loadFlapConfig() {
+	flapThreshold = envInt("FLAP_THRESHOLD", 4)
+	flapWindow = max(envInt("FLAP_WINDOW", 10), 2)
+	if flapThreshold > 0 {
+		logInfo("〰️ Détection d'instabilité : plus de %d changement(s) d'état sur %d vérifications", flapThreshold, flapWindow)
+	}
+}
+
+// recordFlapState ajoute l'état d'une vérification à la fenêtre du site
+// et indique si le site est instable
+func recordFlapState(id, state string) bool {
+	if flapThreshold == 0 {
+		return false
+	}
+	flapMutex.Lock()
+	defer flapMutex.Unlock()
+
+	window := append(flapStates[id], state)
+	if len(window) > flapWindow {
+		window = window[len(window)-flapWindow:]
+	}
+	flapStates[id] = window
+
+	changes := 0
+	for i := 1; i < len(window); i++ {
+		if window[i] != window[i-1] {
+			changes++
+		}
+	}
+	return changes > flapThreshold
+}
+
+// forgetFlapStates efface la fenêtre d'un site (supprimé ou recréé)
+func forgetFlapStates(id string) {
+	flapMutex.Lock()
+	delete(flapStates, id)
+	flapMutex.Unlock()
+}
+
+// notifyFlapping signale qu'un site devient instable ou s'est stabilisé
+func notifyFlapping(st SiteStatus) {
+	var text string
+	if st.Flapping {
+		logEvent("warn", siteFields(st.Site), "〰️ %s est instable, alertes de transition suspendues", st.Site.Name)
+		text = fmt.Sprintf("〰️ *%s* est instable : plus de %d changements d'état sur les %d dernières vérifications. Les alertes de transition sont suspendues.",
+			st.Site.Name, flapThreshold, flapWindow)
+	} else {
+		logEvent("info", siteFields(st.Site), "〰️ %s s'est stabilisé (%s), reprise des alertes", st.Site.Name, st.State)
+		text = fmt.Sprintf("〰️ *%s* s'est stabilisé (état actuel : %s), reprise des alertes de transition", st.Site.Name, st.State)
+	}
+	if slackWebhookURL != "" {
+		goNotify("slack", func() { sendSlack(text) })
+	}
+}
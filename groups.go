@@ -0,0 +1,68 @@
+package main
+
+import (
+	"net/http"
+	"sort"
+)
+
+// GroupHealth résume l'état des sites d'un groupe pour une vue « feu tricolore »
+type GroupHealth struct {
+	Group    string `json:"group"`
+	State    string `json:"state"`
+	Up       int    `json:"up"`
+	Degraded int    `json:"degraded"`
+	Down     int    `json:"down"`
+	Pending  int    `json:"pending"`
+	Paused   int    `json:"paused"`
+}
+
+// handleGroups renvoie l'état agrégé de chaque groupe, trié par nom.
+// Un groupe est down si un de ses sites l'est, degraded si un site est dégradé, up sinon ;
+// les sites pas encore vérifiés ou en pause ne comptent que dans pending ou paused.
+func handleGroups(w http.ResponseWriter, r *http.Request) {
+	statusMutex.RLock()
+	byGroup := make(map[string]*GroupHealth)
+	for _, st := range statuses {
+		name := siteGroup(st.Site)
+		g, ok := byGroup[name]
+		if !ok {
+			g = &GroupHealth{Group: name}
+			byGroup[name] = g
+		}
+		switch {
+		case st.State == statePaused:
+			g.Paused++
+		case st.State == statePending:
+			g.Pending++
+		case st.State == stateDegraded:
+			g.Degraded++
+		case st.IsUp:
+			g.Up++
+		default:
+			g.Down++
+		}
+	}
+	statusMutex.RUnlock()
+
+	result := make([]GroupHealth, 0, len(byGroup))
+	for _, g := range byGroup {
+		switch {
+		case g.Down > 0:
+			g.State = stateDown
+		case g.Degraded > 0:
+			g.State = stateDegraded
+		case g.Up > 0:
+			g.State = stateUp
+		case g.Pending == 0 && g.Paused > 0:
+			g.State = statePaused
+		default:
+			g.State = statePending
+		}
+		result = append(result, *g)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Group < result[j].Group })
+
+	w.Header().Set("Content-Type", "application/json")
+	setStatusCacheHeaders(w)
+	writeJSON(w, r, result)
+}
@@ -0,0 +1,173 @@
+package main
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative monitorpb/monitor.proto
+
+import (
+	"context"
+	"net"
+	"os"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"site-monitor/monitorpb"
+)
+
+// grpcMonitor expose les statuts via gRPC en partageant l'état (et statusMutex) de l'API HTTP
+type grpcMonitor struct {
+	monitorpb.UnimplementedMonitorServer
+}
+
+// startGRPCServer démarre le serveur gRPC si GRPC_PORT est défini ; renvoie nil sinon
+func startGRPCServer() *grpc.Server {
+	port := os.Getenv("GRPC_PORT")
+	if port == "" {
+		return nil
+	}
+	lis, err := net.Listen("tcp", ":"+port)
+	if err != nil {
+		logFatal("❌ Impossible d'écouter sur le port gRPC %s : %v", port, err)
+	}
+
+	srv := grpc.NewServer(grpcAuthOptions(os.Getenv("API_KEY"))...)
+	monitorpb.RegisterMonitorServer(srv, &grpcMonitor{})
+	go func() {
+		logInfo("🚀 Serveur gRPC démarré sur le port %s", port)
+		if err := srv.Serve(lis); err != nil {
+			logError("Le serveur gRPC s’est arrêté : %v", err)
+		}
+	}()
+	return srv
+}
+
+// grpcAuthOptions exige, comme authMiddleware pour l'API HTTP, la clé API_KEY dans la
+// métadonnée authorization ("Bearer <clé>" ou la clé seule) de chaque appel, flux compris.
+// Sans API_KEY, le serveur reste ouvert.
+func grpcAuthOptions(key string) []grpc.ServerOption {
+	if key == "" {
+		return nil
+	}
+	logInfo("🔒 Authentification par clé d'API activée pour gRPC")
+	return []grpc.ServerOption{
+		grpc.UnaryInterceptor(func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			if err := grpcAuthorize(ctx, key); err != nil {
+				return nil, err
+			}
+			return handler(ctx, req)
+		}),
+		grpc.StreamInterceptor(func(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			if err := grpcAuthorize(ss.Context(), key); err != nil {
+				return err
+			}
+			return handler(srv, ss)
+		}),
+	}
+}
+
+// grpcAuthorize vérifie la clé portée par la métadonnée authorization d'un appel entrant
+func grpcAuthorize(ctx context.Context, key string) error {
+	md, _ := metadata.FromIncomingContext(ctx)
+	var candidates []string
+	for _, v := range md.Get("authorization") {
+		candidates = append(candidates, strings.TrimPrefix(v, "Bearer "))
+	}
+	if !matchesAPIKey(key, candidates...) {
+		return status.Error(codes.Unauthenticated, "clé d'API manquante ou invalide")
+	}
+	return nil
+}
+
+// stopGRPCServer arrête proprement le serveur gRPC, ou brutalement si ctx expire
+// (les flux WatchStatus ouverts empêcheraient sinon GracefulStop de rendre la main).
+// Renvoie false si des appels ont dû être interrompus.
+func stopGRPCServer(ctx context.Context, srv *grpc.Server) bool {
+	done := make(chan struct{})
+	go func() {
+		srv.GracefulStop()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return true
+	case <-ctx.Done():
+		srv.Stop()
+		return false
+	}
+}
+
+// GetStatus renvoie le statut actuel de tous les sites
+func (g *grpcMonitor) GetStatus(ctx context.Context, _ *monitorpb.GetStatusRequest) (*monitorpb.GetStatusResponse, error) {
+	statusMutex.RLock()
+	defer statusMutex.RUnlock()
+	return toProtoStatuses(statuses), nil
+}
+
+// GetStatusByID renvoie le statut d'un seul site
+func (g *grpcMonitor) GetStatusByID(ctx context.Context, req *monitorpb.GetStatusByIDRequest) (*monitorpb.SiteStatus, error) {
+	statusMutex.RLock()
+	defer statusMutex.RUnlock()
+	for _, st := range statuses {
+		if st.Site.ID == req.GetId() {
+			return toProtoStatus(st), nil
+		}
+	}
+	return nil, status.Errorf(codes.NotFound, "site %q introuvable", req.GetId())
+}
+
+// WatchStatus envoie l'état courant puis un nouvel instantané après chaque passe
+func (g *grpcMonitor) WatchStatus(_ *monitorpb.WatchStatusRequest, stream monitorpb.Monitor_WatchStatusServer) error {
+	updates, unsubscribe := subscribeStatuses()
+	defer unsubscribe()
+
+	statusMutex.RLock()
+	current := toProtoStatuses(statuses)
+	statusMutex.RUnlock()
+	if err := stream.Send(current); err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case snapshot := <-updates:
+			if err := stream.Send(toProtoStatuses(snapshot)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func toProtoStatuses(list []SiteStatus) *monitorpb.GetStatusResponse {
+	resp := &monitorpb.GetStatusResponse{Statuses: make([]*monitorpb.SiteStatus, len(list))}
+	for i, st := range list {
+		resp.Statuses[i] = toProtoStatus(st)
+	}
+	return resp
+}
+
+func toProtoStatus(st SiteStatus) *monitorpb.SiteStatus {
+	return &monitorpb.SiteStatus{
+		Site: &monitorpb.Site{
+			Id:          st.Site.ID,
+			Name:        st.Site.Name,
+			Url:         redactURLPassword(st.Site.URL),
+			Description: st.Site.Description,
+			Runbook:     st.Site.Runbook,
+		},
+		IsUp:           st.IsUp,
+		State:          st.State,
+		ResponseTimeMs: st.ResponseTime,
+		ResponseTimeUs: st.ResponseTimeUs,
+		StatusCode:     int32(st.StatusCode),
+		LastChecked:    timestamppb.New(st.LastChecked),
+		Error:          st.Error,
+		Protocol:       st.Protocol,
+		Location:       st.Location,
+		BytesRead:      st.BytesRead,
+	}
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestGRPCAuthorize(t *testing.T) {
+	tests := []struct {
+		name string
+		md   metadata.MD
+		ok   bool
+	}{
+		{"sans métadonnée", nil, false},
+		{"Bearer", metadata.Pairs("authorization", "Bearer secret"), true},
+		{"clé seule", metadata.Pairs("authorization", "secret"), true},
+		{"mauvaise clé", metadata.Pairs("authorization", "Bearer autre"), false},
+		{"Bearer vide", metadata.Pairs("authorization", "Bearer "), false},
+		{"autre métadonnée", metadata.Pairs("x-api-key", "secret"), false},
+	}
+	for _, tt := range tests {
+		ctx := context.Background()
+		if tt.md != nil {
+			ctx = metadata.NewIncomingContext(ctx, tt.md)
+		}
+		err := grpcAuthorize(ctx, "secret")
+		if tt.ok != (err == nil) {
+			t.Errorf("%s : erreur %v, accès attendu : %v", tt.name, err, tt.ok)
+		}
+		if err != nil && status.Code(err) != codes.Unauthenticated {
+			t.Errorf("%s : code %s, attendu Unauthenticated", tt.name, status.Code(err))
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// grpcTarget renvoie l'adresse host:port d'un site de type grpc et indique s'il faut du TLS :
+// grpcs:// chiffre la connexion, grpc:// (ou aucun préfixe) la laisse en clair
+func grpcTarget(site Site) (addr string, useTLS bool) {
+	if rest, ok := strings.CutPrefix(site.URL, "grpcs://"); ok {
+		return rest, true
+	}
+	return strings.TrimPrefix(site.URL, "grpc://"), false
+}
+
+// validateGRPCTarget vérifie qu'un site grpc désigne bien une adresse host:port
+func validateGRPCTarget(site Site) error {
+	addr, _ := grpcTarget(site)
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil || host == "" || port == "" {
+		return fmt.Errorf("cible %q invalide (host:port attendu, préfixe grpc:// ou grpcs:// facultatif)", site.URL)
+	}
+	if needsBody(site) {
+		return fmt.Errorf("les assertions sur le corps ne s'appliquent pas aux sites grpc")
+	}
+	return nil
+}
+
+// checkGRPC appelle grpc.health.v1.Health/Check : seul SERVING est up.
+// La durée mesurée couvre la connexion et l'appel. User-Agent et proxy suivent les mêmes
+// règles que les vérifications HTTP.
+func checkGRPC(site Site) SiteStatus {
+	addr, useTLS := grpcTarget(site)
+	creds := insecure.NewCredentials()
+	if useTLS {
+		creds = credentials.NewTLS(&tls.Config{})
+	}
+	dialer := newDialer()
+	if site.SourceIP != "" {
+		dialer.LocalAddr = &net.TCPAddr{IP: net.ParseIP(site.SourceIP)}
+	}
+	network := siteNetwork(site)
+	ua := site.UserAgent
+	if ua == "" {
+		ua = userAgent
+	}
+
+	// Via un proxy, la cible part telle quelle (passthrough) pour que le proxy la résolve lui-même
+	target := addr
+	proxyURL := siteProxy(site, addr, useTLS)
+	if proxyURL != nil {
+		target = "passthrough:///" + addr
+	}
+
+	status := SiteStatus{Site: site, RequestStartedAt: time.Now()}
+	conn, err := grpc.NewClient(target,
+		grpc.WithTransportCredentials(creds),
+		grpc.WithUserAgent(ua),
+		grpc.WithContextDialer(func(ctx context.Context, addr string) (net.Conn, error) {
+			if proxyURL != nil {
+				return dialViaProxy(ctx, dialer, network, proxyURL, addr)
+			}
+			return dialer.DialContext(ctx, network, addr)
+		}),
+	)
+	if err != nil {
+		status.Error = err.Error()
+		status.State = stateDown
+		status.LastChecked = time.Now()
+		return status
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), siteTimeout(site))
+	defer cancel()
+	if len(site.Headers) > 0 {
+		ctx = metadata.NewOutgoingContext(ctx, metadata.New(site.Headers))
+	}
+
+	var p peer.Peer
+	start := time.Now()
+	resp, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{Service: site.GRPCService}, grpc.Peer(&p))
+	elapsed := time.Since(start)
+	status.ResponseTime = elapsed.Milliseconds()
+	status.ResponseTimeUs = elapsed.Microseconds()
+	status.RequestStartedAt = start
+	if p.Addr != nil {
+		status.RemoteAddr = p.Addr.String()
+	}
+	if p.LocalAddr != nil && site.SourceIP != "" {
+		status.SourceAddr = p.LocalAddr.String()
+	}
+
+	switch {
+	case err != nil:
+		status.Error = err.Error()
+		status.ErrorType = classifyGRPCError(err)
+		status.State = stateDown
+	case resp.GetStatus() != healthpb.HealthCheckResponse_SERVING:
+		status.Error = fmt.Sprintf("statut gRPC %s", resp.GetStatus())
+		status.State = stateDown
+	default:
+		status.IsUp = true
+		status.State = stateUp
+	}
+	status.LastChecked = time.Now()
+	return status
+}
+
+// classifyGRPCError range une erreur d'appel gRPC dans les mêmes catégories que les vérifications HTTP.
+// Les erreurs réseau sous-jacentes n'étant pas accessibles, on se fie au code et au message.
+func classifyGRPCError(err error) string {
+	st := status.Convert(err)
+	switch {
+	case st.Code() == codes.DeadlineExceeded:
+		return errorTypeTimeout
+	case st.Code() != codes.Unavailable:
+		// Le serveur a répondu, par une erreur applicative (service inconnu, non implémenté...)
+		return errorTypeOther
+	}
+	msg := st.Message()
+	switch {
+	case strings.Contains(msg, "connection refused"):
+		return errorTypeConnectionRefused
+	case strings.Contains(msg, "no such host"):
+		return errorTypeDNS
+	case strings.Contains(msg, "tls:") || strings.Contains(msg, "x509:"):
+		return errorTypeTLS
+	}
+	return errorTypeOther
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"net/http"
+	"time"
+)
+
+// stalenessIntervals est le nombre d'intervalles sans passe au-delà duquel
+// la boucle de monitoring est considérée comme bloquée
+const stalenessIntervals = 2
+
+// lastPassAge renvoie le temps écoulé depuis la dernière passe terminée
+// (depuis le démarrage si aucune passe n'a encore abouti)
+func lastPassAge() time.Duration {
+	passMetricsMutex.Lock()
+	last := lastPassTimestamp
+	passMetricsMutex.Unlock()
+	if last.IsZero() {
+		last = startTime
+	}
+	return time.Since(last)
+}
+
+// monitoringStale indique si aucune passe n'a abouti depuis plus de stalenessIntervals fois
+// la cadence la plus lente (ralentissement des sites en échec compris), augmentée de la durée
+// maximale d'une vérification pour qu'une passe lente ne soit pas prise pour un blocage.
+// Sans site actif (aucun site, ou tous en pause), aucune passe n'est attendue.
+func monitoringStale() bool {
+	return passStale(activeSites(currentSites()), siteIntervals(), lastPassAge())
+}
+
+// passStale applique la règle de monitoringStale aux sites actifs donnés
+func passStale(active []Site, intervals intervalTable, age time.Duration) bool {
+	if len(active) == 0 {
+		return false
+	}
+	var slowest, longestCheck time.Duration
+	for _, s := range active {
+		slowest = max(slowest, intervals.of(s))
+		// Les endpoints d'un site sont sondés l'un après l'autre
+		probes := time.Duration(max(len(s.Endpoints), 1))
+		longestCheck = max(longestCheck, siteTimeout(s)*time.Duration(s.RetryCount+1)*probes)
+	}
+	return age > stalenessIntervals*slowest+longestCheck
+}
+
+// handleLive répond 200 tant que le processus sert des requêtes (sonde de vivacité)
+func handleLive(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(w, r, map[string]string{"status": "alive"})
+}
+
+// handleReady répond 503 si la boucle de monitoring semble bloquée ou si l'auto-test
+// de démarrage obligatoire a échoué (sonde de disponibilité)
+func handleReady(w http.ResponseWriter, r *http.Request) {
+	state, reason := "ready", ""
+	switch {
+	case canaryBlocksReadiness():
+		state, reason = "not_ready", "canary_failed"
+	case monitoringStale():
+		state, reason = "not_ready", "monitoring_stale"
+	}
+
+	code := http.StatusOK
+	if state != "ready" {
+		code = http.StatusServiceUnavailable
+	}
+	resp := map[string]interface{}{
+		"status":                state,
+		"last_pass_age_seconds": int(lastPassAge().Seconds()),
+	}
+	if reason != "" {
+		resp["reason"] = reason
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(code)
+	writeJSON(w, r, resp)
+}
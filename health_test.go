@@ -0,0 +1,54 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPassStale(t *testing.T) {
+	fast := Site{ID: "fast", IntervalSeconds: 10, TimeoutSeconds: 5}
+	slow := Site{ID: "slow", IntervalSeconds: 60, TimeoutSeconds: 5, RetryCount: 1}
+	multi := Site{ID: "multi", IntervalSeconds: 10, TimeoutSeconds: 5, Endpoints: []string{"http://a", "http://b", "http://c"}}
+
+	tests := []struct {
+		name      string
+		active    []Site
+		intervals intervalTable
+		age       time.Duration
+		want      bool
+	}{
+		{"aucun site actif", nil, nil, 24 * time.Hour, false},
+		{"passe récente", []Site{fast}, nil, 10 * time.Second, false},
+		// 2 × 10s + 5s de vérification
+		{"juste sous le seuil", []Site{fast}, nil, 25 * time.Second, false},
+		{"au-delà du seuil", []Site{fast}, nil, 26 * time.Second, true},
+		// La cadence la plus lente fixe le seuil : 2 × 60s + 2 × 5s (une nouvelle tentative)
+		{"site lent", []Site{fast, slow}, nil, 125 * time.Second, false},
+		{"site lent en retard", []Site{fast, slow}, nil, 131 * time.Second, true},
+		// Backoff : la cadence ralentie du site en échec est prise en compte
+		{"cadence ralentie", []Site{fast}, intervalTable{"fast": 40 * time.Second}, 80 * time.Second, false},
+		// Trois endpoints sondés l'un après l'autre : 2 × 10s + 3 × 5s
+		{"multi-endpoints", []Site{multi}, nil, 34 * time.Second, false},
+		{"multi-endpoints en retard", []Site{multi}, nil, 36 * time.Second, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := passStale(tt.active, tt.intervals, tt.age); got != tt.want {
+				t.Errorf("passStale() = %v, attendu %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMonitoringStaleAllPaused(t *testing.T) {
+	oldSites, oldStatuses := sites, statuses
+	defer func() { sites, statuses = oldSites, oldStatuses }()
+
+	// Aucune passe depuis le démarrage, mais tous les sites sont en pause : rien n'est attendu
+	paused := Site{ID: "p", IntervalSeconds: 1, TimeoutSeconds: 1, Paused: true}
+	sites = []Site{paused}
+	statuses = []SiteStatus{{Site: paused, State: statePaused}}
+	if monitoringStale() {
+		t.Error("monitoringStale() = true alors que tous les sites sont en pause")
+	}
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+)
+
+// Battement de cœur sortant (HEARTBEAT_URL) : un moniteur externe de type healthchecks.io
+// alerte quand les pings cessent, que le processus soit mort ou sa boucle bloquée
+var (
+	heartbeatURL      string
+	heartbeatInterval time.Duration
+)
+
+// heartbeatClient borne chaque ping pour qu'un récepteur lent ne retarde pas le suivant
+var heartbeatClient = &http.Client{Timeout: 10 * time.Second}
+
+// loadHeartbeatConfig lit HEARTBEAT_URL et HEARTBEAT_INTERVAL_SECONDS (60 par défaut)
+func loadHeartbeatConfig() {
+	heartbeatURL = os.Getenv("HEARTBEAT_URL")
+	if heartbeatURL == "" {
+		return
+	}
+	seconds := envInt("HEARTBEAT_INTERVAL_SECONDS", 60)
+	if seconds == 0 {
+		logWarn("⚠️ HEARTBEAT_INTERVAL_SECONDS doit être strictement positif, intervalle par défaut utilisé")
+		seconds = 60
+	}
+	heartbeatInterval = time.Duration(seconds) * time.Second
+	logInfo("💓 Battement de cœur activé : toutes les %s", heartbeatInterval)
+}
+
+// runHeartbeat envoie un ping à chaque intervalle tant que le monitoring est à jour.
+// Une boucle de vérification bloquée interrompt les pings, comme un processus arrêté.
+func runHeartbeat(ctx context.Context) {
+	if heartbeatURL == "" {
+		return
+	}
+	ticker := time.NewTicker(heartbeatInterval)
+	defer ticker.Stop()
+
+	skipping := false
+	for {
+		if monitoringStale() {
+			if !skipping {
+				logWarn("💔 Monitoring en retard (dernière passe il y a %s) : battements de cœur suspendus", lastPassAge().Round(time.Second))
+			}
+			skipping = true
+		} else {
+			if skipping {
+				logInfo("💓 Monitoring à jour, reprise des battements de cœur")
+			}
+			skipping = false
+			if err := sendHeartbeat(ctx); err != nil && ctx.Err() == nil {
+				logWarn("⚠️ Battement de cœur non délivré : %v", err)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// sendHeartbeat envoie un ping et considère tout code >= 300 comme un échec
+func sendHeartbeat(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, heartbeatURL, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("User-Agent", userAgent)
+	resp, err := heartbeatClient.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("code %d", resp.StatusCode)
+	}
+	return nil
+}
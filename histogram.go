@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// defaultHistogramBounds découpe les temps de réponse en 0-100ms, 100-300ms, 300-1000ms et 1s+
+var defaultHistogramBounds = []int64{100, 300, 1000}
+
+// HistogramBucket compte les vérifications dont le temps de réponse est dans [min_ms, max_ms[.
+// max_ms est null pour le dernier intervalle, ouvert.
+type HistogramBucket struct {
+	MinMs int64  `json:"min_ms"`
+	MaxMs *int64 `json:"max_ms"`
+	Count int    `json:"count"`
+}
+
+// ResponseHistogram est la distribution des temps de réponse d'un site sur l'historique conservé
+type ResponseHistogram struct {
+	ID      string            `json:"id"`
+	Samples int               `json:"samples"`
+	Buckets []HistogramBucket `json:"buckets"`
+}
+
+// parseHistogramBounds lit des bornes en millisecondes séparées par des virgules,
+// strictement croissantes et positives (ex. "50,200,500")
+func parseHistogramBounds(raw string) ([]int64, error) {
+	var bounds []int64
+	for _, part := range strings.Split(raw, ",") {
+		n, err := strconv.ParseInt(strings.TrimSpace(part), 10, 64)
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("borne invalide %q", part)
+		}
+		if len(bounds) > 0 && n <= bounds[len(bounds)-1] {
+			return nil, fmt.Errorf("les bornes doivent être strictement croissantes (%d après %d)", n, bounds[len(bounds)-1])
+		}
+		bounds = append(bounds, n)
+	}
+	return bounds, nil
+}
+
+// computeHistogram répartit les temps de réponse entre les intervalles délimités par bounds ;
+// sans échantillon, tous les intervalles sont présents avec un compte nul
+func computeHistogram(records []checkRecord, bounds []int64) []HistogramBucket {
+	buckets := make([]HistogramBucket, len(bounds)+1)
+	for i, b := range bounds {
+		buckets[i].MaxMs = &b
+		buckets[i+1].MinMs = b
+	}
+	for _, rec := range records {
+		i := 0
+		for i < len(bounds) && rec.ResponseTime >= bounds[i] {
+			i++
+		}
+		buckets[i].Count++
+	}
+	return buckets
+}
+
+// handleHistogram renvoie la distribution des temps de réponse d'un site (?id=, ?buckets= facultatif)
+func handleHistogram(w http.ResponseWriter, r *http.Request) {
+	id := r.URL.Query().Get("id")
+	if id == "" {
+		http.Error(w, "Paramètre id requis", http.StatusBadRequest)
+		return
+	}
+
+	bounds := defaultHistogramBounds
+	if v := r.URL.Query().Get("buckets"); v != "" {
+		var err error
+		if bounds, err = parseHistogramBounds(v); err != nil {
+			http.Error(w, "Paramètre buckets invalide : "+err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	statusMutex.RLock()
+	_, known := findSite(id)
+	statusMutex.RUnlock()
+	if !known {
+		http.Error(w, "Site introuvable", http.StatusNotFound)
+		return
+	}
+
+	records := siteHistory(id)
+	w.Header().Set("Content-Type", "application/json")
+	setStatusCacheHeaders(w)
+	writeJSON(w, r, ResponseHistogram{
+		ID:      id,
+		Samples: len(records),
+		Buckets: computeHistogram(records, bounds),
+	})
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"math"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// checkRecord est une entrée de l'historique d'un site
+type checkRecord struct {
+	At           time.Time
+	IsUp         bool
+	ResponseTime int64
+	StatusCode   int
+}
+
+// ringBuffer conserve les N derniers résultats d'un site
+type ringBuffer struct {
+	records []checkRecord
+	next    int
+	full    bool
+}
+
+func newRingBuffer(size int) *ringBuffer {
+	return &ringBuffer{records: make([]checkRecord, size)}
+}
+
+func (b *ringBuffer) add(r checkRecord) {
+	b.records[b.next] = r
+	b.next = (b.next + 1) % len(b.records)
+	if b.next == 0 {
+		b.full = true
+	}
+}
+
+// all renvoie une copie des entrées, de la plus ancienne à la plus récente
+func (b *ringBuffer) all() []checkRecord {
+	if !b.full {
+		return append([]checkRecord(nil), b.records[:b.next]...)
+	}
+	out := make([]checkRecord, 0, len(b.records))
+	out = append(out, b.records[b.next:]...)
+	return append(out, b.records[:b.next]...)
+}
+
+// dropBefore retire les entrées antérieures à cutoff et renvoie leur nombre.
+// Les entrées étant chronologiques, les plus anciennes sont en tête de all().
+func (b *ringBuffer) dropBefore(cutoff time.Time) int {
+	records := b.all()
+	n := 0
+	for n < len(records) && records[n].At.Before(cutoff) {
+		n++
+	}
+	if n == 0 {
+		return 0
+	}
+	kept := records[n:]
+	b.records = make([]checkRecord, len(b.records))
+	copy(b.records, kept)
+	b.next = len(kept)
+	b.full = false
+	return n
+}
+
+// resize change la capacité du tampon en gardant les entrées les plus récentes
+func (b *ringBuffer) resize(size int) {
+	records := b.all()
+	if len(records) > size {
+		records = records[len(records)-size:]
+	}
+	b.records = make([]checkRecord, size)
+	copy(b.records, records)
+	b.next = len(records) % size
+	b.full = len(records) == size
+}
+
+// Historique borné par site, protégé par son propre verrou pour ne pas
+// rallonger les sections critiques de statusMutex
+var (
+	historySize  int
+	history      = make(map[string]*ringBuffer)
+	historyMutex sync.RWMutex
+)
+
+// loadHistoryConfig lit HISTORY_SIZE (nombre de vérifications conservées par site, 1000 par défaut)
+func loadHistoryConfig() {
+	historySize = envInt("HISTORY_SIZE", 1000)
+	if historySize <= 0 {
+		historySize = 1000
+	}
+}
+
+// recordHistory ajoute les résultats d'une passe à l'historique de chaque site
+func recordHistory(results []SiteStatus) {
+	historyMutex.Lock()
+	defer historyMutex.Unlock()
+
+	for _, st := range results {
+		// Une rétention propre au site exprimée en nombre de vérifications remplace HISTORY_SIZE
+		size := historySize
+		if r := historyRetentionOf(st.Site); r.count > 0 {
+			size = r.count
+		}
+		buf, ok := history[st.Site.ID]
+		if !ok {
+			buf = newRingBuffer(size)
+			history[st.Site.ID] = buf
+		} else if len(buf.records) != size {
+			buf.resize(size)
+		}
+		buf.add(checkRecord{At: st.LastChecked, IsUp: st.IsUp, ResponseTime: st.ResponseTime, StatusCode: st.StatusCode})
+		// Rétention en durée propre au site : appliquée à chaque ajout, la base suit à la purge horaire
+		if r := historyRetentionOf(st.Site); r.age > 0 {
+			buf.dropBefore(st.LastChecked.Add(-r.age))
+		}
+	}
+}
+
+// siteHistory renvoie une copie de l'historique d'un site
+func siteHistory(id string) []checkRecord {
+	historyMutex.RLock()
+	defer historyMutex.RUnlock()
+	if buf, ok := history[id]; ok {
+		return buf.all()
+	}
+	return nil
+}
+
+// forgetHistory efface l'historique en mémoire d'un site (supprimé ou recréé) et clôt son incident ouvert
+func forgetHistory(id string) {
+	historyMutex.Lock()
+	delete(history, id)
+	historyMutex.Unlock()
+	closeSiteIncident(id, time.Now(), incidentEndSiteRemoved)
+	forgetFlapStates(id)
+	forgetEscalation(id)
+	forgetDeferredAlert(id)
+}
+
+// UptimeReport résume la disponibilité d'un site sur l'historique conservé
+type UptimeReport struct {
+	ID             string   `json:"id"`
+	ChecksRecorded int      `json:"checks_recorded"`
+	UpCount        int      `json:"up_count"`
+	UptimePct      *float64 `json:"uptime_pct"` // null tant qu'aucune vérification n'est enregistrée
+}
+
+// handleUptime renvoie le pourcentage de disponibilité de chaque site
+func handleUptime(w http.ResponseWriter, r *http.Request) {
+	statusMutex.RLock()
+	ids := make([]string, len(sites))
+	for i, s := range sites {
+		ids[i] = s.ID
+	}
+	statusMutex.RUnlock()
+
+	reports := make([]UptimeReport, len(ids))
+	for i, id := range ids {
+		records := siteHistory(id)
+		rep := UptimeReport{ID: id, ChecksRecorded: len(records)}
+		for _, rec := range records {
+			if rec.IsUp {
+				rep.UpCount++
+			}
+		}
+		if rep.ChecksRecorded > 0 {
+			pct := math.Round(float64(rep.UpCount)/float64(rep.ChecksRecorded)*10000) / 100
+			rep.UptimePct = &pct
+		}
+		reports[i] = rep
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	setStatusCacheHeaders(w)
+	writeJSON(w, r, reports)
+}
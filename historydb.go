@@ -0,0 +1,190 @@
+package main
+
+import (
+	"database/sql"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+// historyDB persiste chaque résultat de vérification ; nil si DB_PATH n'est pas défini
+// (l'historique reste alors uniquement en mémoire)
+var historyDB *sql.DB
+
+const (
+	defaultHistoryLimit = 100
+	maxHistoryLimit     = 1000
+)
+
+// openHistoryDB ouvre la base SQLite désignée par DB_PATH et crée les tables si besoin
+func openHistoryDB() error {
+	path := os.Getenv("DB_PATH")
+	if path == "" {
+		return nil
+	}
+
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return err
+	}
+	// SQLite n'accepte qu'un écrivain à la fois
+	db.SetMaxOpenConns(1)
+
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS check_results (
+			site_id          TEXT    NOT NULL,
+			checked_at       INTEGER NOT NULL,
+			is_up            INTEGER NOT NULL,
+			response_time_ms INTEGER NOT NULL,
+			status_code      INTEGER NOT NULL
+		);
+		CREATE INDEX IF NOT EXISTS idx_check_results_site ON check_results (site_id, checked_at);
+		CREATE TABLE IF NOT EXISTS incidents (
+			id         INTEGER PRIMARY KEY AUTOINCREMENT,
+			site_id    TEXT    NOT NULL,
+			started_at INTEGER NOT NULL,
+			ended_at   INTEGER,
+			error      TEXT    NOT NULL,
+			end_reason TEXT    NOT NULL DEFAULT ''
+		);
+		CREATE INDEX IF NOT EXISTS idx_incidents_site ON incidents (site_id, started_at);
+	`)
+	if err != nil {
+		db.Close()
+		return err
+	}
+	// Bases créées avant end_reason : la colonne est ajoutée à l'ouverture
+	if _, err := db.Exec(`SELECT end_reason FROM incidents LIMIT 0`); err != nil {
+		if _, err := db.Exec(`ALTER TABLE incidents ADD COLUMN end_reason TEXT NOT NULL DEFAULT ''`); err != nil {
+			db.Close()
+			return err
+		}
+	}
+
+	historyDB = db
+	logInfo("🗄️ Historique persisté dans %s", path)
+	return nil
+}
+
+// closeHistoryDB ferme la base à l'arrêt du serveur
+func closeHistoryDB() {
+	if historyDB != nil {
+		historyDB.Close()
+	}
+}
+
+// persistHistory insère une ligne par site pour la passe, dans une seule transaction
+func persistHistory(results []SiteStatus) {
+	if historyDB == nil || len(results) == 0 {
+		return
+	}
+
+	tx, err := historyDB.Begin()
+	if err != nil {
+		logWarn("⚠️ Historique non persisté : %v", err)
+		return
+	}
+	stmt, err := tx.Prepare(`INSERT INTO check_results (site_id, checked_at, is_up, response_time_ms, status_code) VALUES (?, ?, ?, ?, ?)`)
+	if err != nil {
+		tx.Rollback()
+		logWarn("⚠️ Historique non persisté : %v", err)
+		return
+	}
+	defer stmt.Close()
+
+	for _, st := range results {
+		if _, err := stmt.Exec(st.Site.ID, st.LastChecked.UnixMilli(), st.IsUp, st.ResponseTime, st.StatusCode); err != nil {
+			tx.Rollback()
+			logWarn("⚠️ Historique non persisté : %v", err)
+			return
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		logWarn("⚠️ Historique non persisté : %v", err)
+	}
+}
+
+// HistoryEntry est une vérification passée renvoyée par /api/history
+type HistoryEntry struct {
+	SiteID       string    `json:"site_id"`
+	CheckedAt    time.Time `json:"checked_at"`
+	IsUp         bool      `json:"is_up"`
+	ResponseTime int64     `json:"response_time_ms"`
+	StatusCode   int       `json:"status_code"`
+}
+
+// handleHistory renvoie les dernières vérifications d'un site, de la plus récente à la plus ancienne.
+// Sans base configurée, l'historique en mémoire est utilisé.
+func handleHistory(w http.ResponseWriter, r *http.Request) {
+	id := r.URL.Query().Get("id")
+	if id == "" {
+		http.Error(w, "Paramètre id requis", http.StatusBadRequest)
+		return
+	}
+
+	limit := defaultHistoryLimit
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			http.Error(w, "Paramètre limit invalide", http.StatusBadRequest)
+			return
+		}
+		limit = min(n, maxHistoryLimit)
+	}
+
+	statusMutex.RLock()
+	_, known := findSite(id)
+	statusMutex.RUnlock()
+	if !known {
+		http.Error(w, "Site introuvable", http.StatusNotFound)
+		return
+	}
+
+	var entries []HistoryEntry
+	if historyDB != nil {
+		var err error
+		entries, err = queryHistory(id, limit)
+		if err != nil {
+			logWarn("⚠️ Lecture de l'historique impossible : %v", err)
+			http.Error(w, "Historique indisponible", http.StatusInternalServerError)
+			return
+		}
+	} else {
+		records := siteHistory(id)
+		for i := len(records) - 1; i >= 0 && len(entries) < limit; i-- {
+			rec := records[i]
+			entries = append(entries, HistoryEntry{SiteID: id, CheckedAt: rec.At, IsUp: rec.IsUp, ResponseTime: rec.ResponseTime, StatusCode: rec.StatusCode})
+		}
+	}
+	if entries == nil {
+		entries = []HistoryEntry{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	setStatusCacheHeaders(w)
+	writeJSON(w, r, entries)
+}
+
+// queryHistory lit les dernières lignes d'un site dans la base
+func queryHistory(id string, limit int) ([]HistoryEntry, error) {
+	rows, err := historyDB.Query(`SELECT checked_at, is_up, response_time_ms, status_code FROM check_results WHERE site_id = ? ORDER BY checked_at DESC LIMIT ?`, id, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []HistoryEntry
+	for rows.Next() {
+		e := HistoryEntry{SiteID: id}
+		var checkedAt int64
+		if err := rows.Scan(&checkedAt, &e.IsUp, &e.ResponseTime, &e.StatusCode); err != nil {
+			return nil, err
+		}
+		e.CheckedAt = time.UnixMilli(checkedAt).UTC()
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
@@ -0,0 +1,259 @@
+package main
+
+import (
+	"database/sql"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Incident est une période d'indisponibilité d'un site ; EndedAt est nil tant qu'elle dure
+type Incident struct {
+	SiteID          string     `json:"site_id"`
+	StartedAt       time.Time  `json:"started_at"`
+	EndedAt         *time.Time `json:"ended_at"`
+	DurationSeconds float64    `json:"duration_seconds"`
+	Duration        string     `json:"duration"`
+	Ongoing         bool       `json:"ongoing"`
+	Error           string     `json:"error,omitempty"`      // cause constatée à l'ouverture
+	EndReason       string     `json:"end_reason,omitempty"` // clôture autre qu'un rétablissement (ex. site supprimé)
+
+	dbID int64 // ligne correspondante dans la base, 0 sans DB_PATH
+}
+
+// withDuration calcule la durée de l'incident (jusqu'à now s'il est en cours)
+func (inc Incident) withDuration(now time.Time) Incident {
+	end := now
+	if inc.EndedAt != nil {
+		end = *inc.EndedAt
+	}
+	d := end.Sub(inc.StartedAt).Round(time.Second)
+	inc.DurationSeconds = d.Seconds()
+	inc.Duration = d.String()
+	inc.Ongoing = inc.EndedAt == nil
+	return inc
+}
+
+// Incidents par site : l'incident ouvert à part, les incidents clos dans une liste bornée
+var (
+	maxIncidents    int
+	openIncidents   = make(map[string]*Incident)
+	closedIncidents = make(map[string][]Incident)
+	incidentMutex   sync.Mutex
+)
+
+// loadIncidentConfig lit INCIDENT_HISTORY (incidents clos conservés par site, 100 par défaut)
+// et reprend depuis la base les incidents encore ouverts au dernier arrêt
+func loadIncidentConfig() {
+	maxIncidents = max(envInt("INCIDENT_HISTORY", 100), 1)
+	if historyDB == nil {
+		return
+	}
+
+	rows, err := historyDB.Query(`SELECT id, site_id, started_at, error FROM incidents WHERE ended_at IS NULL`)
+	if err != nil {
+		logWarn("⚠️ Incidents ouverts non repris : %v", err)
+		return
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var inc Incident
+		var started int64
+		if err := rows.Scan(&inc.dbID, &inc.SiteID, &started, &inc.Error); err != nil {
+			logWarn("⚠️ Incidents ouverts non repris : %v", err)
+			return
+		}
+		inc.StartedAt = time.UnixMilli(started).UTC()
+		openIncidents[inc.SiteID] = &inc
+	}
+}
+
+// trackIncidents ouvre un incident quand un site tombe (hors maintenance) et le clôt à son rétablissement
+func trackIncidents(changes []statusChange) {
+	incidentMutex.Lock()
+	defer incidentMutex.Unlock()
+
+	for _, c := range changes {
+		st := c.Current
+		open := openIncidents[st.Site.ID]
+		switch {
+		case !st.IsUp && !st.Maintenance && open == nil:
+			inc := &Incident{SiteID: st.Site.ID, StartedAt: st.LastChecked, Error: st.Error}
+			if historyDB != nil {
+				res, err := historyDB.Exec(`INSERT INTO incidents (site_id, started_at, error) VALUES (?, ?, ?)`,
+					inc.SiteID, inc.StartedAt.UnixMilli(), inc.Error)
+				if err == nil {
+					inc.dbID, _ = res.LastInsertId()
+				} else {
+					logWarn("⚠️ Incident non persisté : %v", err)
+				}
+			}
+			openIncidents[st.Site.ID] = inc
+		case st.IsUp && open != nil:
+			closeIncident(open, st.LastChecked, "")
+		}
+	}
+}
+
+// closeIncident clôt un incident ouvert à end et le range parmi les incidents clos de son site ;
+// reason est vide pour un rétablissement (incidentMutex détenu)
+func closeIncident(open *Incident, end time.Time, reason string) {
+	open.EndedAt = &end
+	open.EndReason = reason
+	if historyDB != nil && open.dbID != 0 {
+		if _, err := historyDB.Exec(`UPDATE incidents SET ended_at = ?, end_reason = ? WHERE id = ?`, end.UnixMilli(), reason, open.dbID); err != nil {
+			logWarn("⚠️ Fin d'incident non persistée : %v", err)
+		}
+	}
+	closed := append(closedIncidents[open.SiteID], *open)
+	if len(closed) > maxIncidents {
+		closed = closed[len(closed)-maxIncidents:]
+	}
+	closedIncidents[open.SiteID] = closed
+	delete(openIncidents, open.SiteID)
+}
+
+// incidentEndSiteRemoved est la raison de clôture de l'incident d'un site supprimé
+const incidentEndSiteRemoved = "site supprimé"
+
+// closeSiteIncident clôt l'incident encore ouvert d'un site qui disparaît de la configuration
+// (suppression via l'API ou rechargement) : sans lui, il resterait en cours indéfiniment
+func closeSiteIncident(id string, at time.Time, reason string) {
+	incidentMutex.Lock()
+	defer incidentMutex.Unlock()
+
+	if open := openIncidents[id]; open != nil {
+		closeIncident(open, at, reason)
+	}
+}
+
+// pruneClosedIncidents retire de la mémoire les incidents clos terminés avant cutoff (coupure nulle :
+// aucune) puis, au-delà de maxRows incidents clos tous sites confondus (0 : sans limite), les plus
+// anciens. Les sites sans incident restant sont oubliés. Renvoie le nombre d'incidents retirés.
+func pruneClosedIncidents(cutoff time.Time, maxRows int) int {
+	incidentMutex.Lock()
+	defer incidentMutex.Unlock()
+
+	pruned := 0
+	var ends []time.Time
+	for id, closed := range closedIncidents {
+		kept := closed[:0]
+		for _, inc := range closed {
+			if !cutoff.IsZero() && inc.EndedAt.Before(cutoff) {
+				pruned++
+				continue
+			}
+			kept = append(kept, inc)
+			ends = append(ends, *inc.EndedAt)
+		}
+		closedIncidents[id] = kept
+	}
+
+	if maxRows > 0 && len(ends) > maxRows {
+		// Fin du plus récent des incidents en trop : tout incident terminé avant ou à cet instant part
+		sort.Slice(ends, func(i, j int) bool { return ends[i].After(ends[j]) })
+		limit := ends[maxRows]
+		for id, closed := range closedIncidents {
+			kept := closed[:0]
+			for _, inc := range closed {
+				if !inc.EndedAt.After(limit) {
+					pruned++
+					continue
+				}
+				kept = append(kept, inc)
+			}
+			closedIncidents[id] = kept
+		}
+	}
+
+	for id, closed := range closedIncidents {
+		if len(closed) == 0 {
+			delete(closedIncidents, id)
+		}
+	}
+	return pruned
+}
+
+// handleIncidents renvoie les incidents d'un site (?id=) ou de tous les sites, du plus récent au plus ancien.
+// Avec DB_PATH, l'historique complet est lu depuis la base.
+func handleIncidents(w http.ResponseWriter, r *http.Request) {
+	id := r.URL.Query().Get("id")
+	if id != "" {
+		statusMutex.RLock()
+		_, known := findSite(id)
+		statusMutex.RUnlock()
+		if !known {
+			http.Error(w, "Site introuvable", http.StatusNotFound)
+			return
+		}
+	}
+
+	var list []Incident
+	if historyDB != nil {
+		var err error
+		if list, err = queryIncidents(id); err != nil {
+			logError("❌ Lecture des incidents impossible : %v", err)
+			http.Error(w, "Incidents indisponibles", http.StatusInternalServerError)
+			return
+		}
+	} else {
+		incidentMutex.Lock()
+		for siteID, closed := range closedIncidents {
+			if id == "" || siteID == id {
+				list = append(list, closed...)
+			}
+		}
+		for siteID, open := range openIncidents {
+			if id == "" || siteID == id {
+				list = append(list, *open)
+			}
+		}
+		incidentMutex.Unlock()
+	}
+
+	now := time.Now()
+	for i := range list {
+		list[i] = list[i].withDuration(now)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].StartedAt.After(list[j].StartedAt) })
+	if list == nil {
+		list = []Incident{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	setStatusCacheHeaders(w)
+	writeJSON(w, r, list)
+}
+
+// queryIncidents lit les incidents depuis la base (les plus récents, dans la limite d'une page)
+func queryIncidents(id string) ([]Incident, error) {
+	var rows *sql.Rows
+	var err error
+	if id == "" {
+		rows, err = historyDB.Query(`SELECT site_id, started_at, ended_at, error, end_reason FROM incidents ORDER BY started_at DESC LIMIT ?`, maxHistoryLimit)
+	} else {
+		rows, err = historyDB.Query(`SELECT site_id, started_at, ended_at, error, end_reason FROM incidents WHERE site_id = ? ORDER BY started_at DESC LIMIT ?`, id, maxHistoryLimit)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []Incident
+	for rows.Next() {
+		var inc Incident
+		var started int64
+		var ended sql.NullInt64
+		if err := rows.Scan(&inc.SiteID, &started, &ended, &inc.Error, &inc.EndReason); err != nil {
+			return nil, err
+		}
+		inc.StartedAt = time.UnixMilli(started).UTC()
+		if ended.Valid {
+			end := time.UnixMilli(ended.Int64).UTC()
+			inc.EndedAt = &end
+		}
+		list = append(list, inc)
+	}
+	return list, rows.Err()
+}
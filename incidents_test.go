@@ -0,0 +1,88 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPruneClosedIncidents(t *testing.T) {
+	base := time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC)
+	// closedAt crée un incident clos terminé il y a h heures
+	closedAt := func(id string, h int) Incident {
+		end := base.Add(-time.Duration(h) * time.Hour)
+		return Incident{SiteID: id, StartedAt: end.Add(-time.Minute), EndedAt: &end}
+	}
+
+	tests := []struct {
+		name    string
+		cutoff  time.Time
+		maxRows int
+		want    map[string]int // incidents restants par site
+		pruned  int
+	}{
+		{"aucune règle", time.Time{}, 0, map[string]int{"a": 3, "b": 2}, 0},
+		{"âge", base.Add(-36 * time.Hour), 0, map[string]int{"a": 1, "b": 1}, 3},
+		{"plafond tous sites confondus", time.Time{}, 2, map[string]int{"a": 1, "b": 1}, 3},
+		{"site vidé oublié", base.Add(-12 * time.Hour), 0, map[string]int{"a": 1}, 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			old := closedIncidents
+			defer func() { closedIncidents = old }()
+			closedIncidents = map[string][]Incident{
+				"a": {closedAt("a", 72), closedAt("a", 48), closedAt("a", 1)},
+				"b": {closedAt("b", 60), closedAt("b", 24)},
+			}
+
+			if got := pruneClosedIncidents(tt.cutoff, tt.maxRows); got != tt.pruned {
+				t.Errorf("%d incident(s) retiré(s), attendu %d", got, tt.pruned)
+			}
+			if len(closedIncidents) != len(tt.want) {
+				t.Errorf("sites restants : %v, attendu %v", closedIncidents, tt.want)
+			}
+			for id, n := range tt.want {
+				if len(closedIncidents[id]) != n {
+					t.Errorf("site %s : %d incident(s), attendu %d", id, len(closedIncidents[id]), n)
+				}
+			}
+		})
+	}
+}
+
+func TestCloseSiteIncident(t *testing.T) {
+	oldOpen, oldClosed, oldMax := openIncidents, closedIncidents, maxIncidents
+	defer func() { openIncidents, closedIncidents, maxIncidents = oldOpen, oldClosed, oldMax }()
+	maxIncidents = 10
+
+	start := time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC)
+	now := start.Add(time.Hour)
+	tests := []struct {
+		name       string
+		open       bool
+		wantClosed int
+	}{
+		{"incident ouvert", true, 1},
+		{"aucun incident", false, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			openIncidents = make(map[string]*Incident)
+			closedIncidents = make(map[string][]Incident)
+			if tt.open {
+				openIncidents["gone"] = &Incident{SiteID: "gone", StartedAt: start}
+			}
+
+			closeSiteIncident("gone", now, incidentEndSiteRemoved)
+			if openIncidents["gone"] != nil {
+				t.Errorf("incident toujours ouvert")
+			}
+			closed := closedIncidents["gone"]
+			if len(closed) != tt.wantClosed {
+				t.Fatalf("%d incident(s) clos, attendu %d", len(closed), tt.wantClosed)
+			}
+			if tt.wantClosed > 0 && (!closed[0].EndedAt.Equal(now) || closed[0].EndReason != incidentEndSiteRemoved) {
+				t.Errorf("incident clos à %v (%q), attendu %v (%q)", closed[0].EndedAt, closed[0].EndReason, now, incidentEndSiteRemoved)
+			}
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package main
+
+// Détection d'un moniteur isolé du réseau : si presque tous les sites tombent
+// en même temps, le problème vient plus probablement du moniteur que des sites.
+var (
+	// isolationRatio est la part de sites en panne (0..1) à partir de laquelle
+	// la passe est considérée comme un échec global
+	isolationRatio float64
+
+	// isolationMinSites évite de conclure à une isolation sur trop peu de sites
+	isolationMinSites int
+
+	// monitorIsolated est protégé par statusMutex, comme statuses
+	monitorIsolated bool
+)
+
+const defaultIsolationMinSites = 3
+
+// loadIsolationConfig lit ISOLATION_FAILURE_RATIO (1 par défaut : tous les sites en panne)
+// et ISOLATION_MIN_SITES (3 par défaut)
+func loadIsolationConfig() {
+	isolationRatio = envFloat("ISOLATION_FAILURE_RATIO", 1)
+	if isolationRatio > 1 {
+		isolationRatio = 1
+	}
+	isolationMinSites = max(envInt("ISOLATION_MIN_SITES", defaultIsolationMinSites), 1)
+}
+
+// detectIsolation indique si le dernier statut des sites actifs dépasse le seuil d'échec global.
+// Avec des intervalles propres aux sites, une passe n'en vérifie souvent qu'une partie : le ratio
+// porte donc sur tous les statuts, pas seulement sur ceux de la passe. Les sites en attente, en
+// pause ou simplement redirigés (en échec par choix, pas faute de réseau) ne comptent pas ; avec
+// moins de isolationMinSites sites restants, on ne conclut pas à une isolation.
+func detectIsolation(list []SiteStatus) bool {
+	if isolationRatio == 0 {
+		return false
+	}
+	total, down := 0, 0
+	for _, st := range list {
+		if st.State == statePending || st.State == statePaused || st.State == stateRedirected || st.Site.Paused {
+			continue
+		}
+		total++
+		if !st.IsUp {
+			down++
+		}
+	}
+	if total < isolationMinSites {
+		return false
+	}
+	return float64(down)/float64(total) >= isolationRatio
+}
+
+// setIsolation met à jour l'état d'isolation (statusMutex détenu), le journalise au changement
+// et indique si l'état a changé
+func setIsolation(isolated bool) bool {
+	if isolated == monitorIsolated {
+		return false
+	}
+	monitorIsolated = isolated
+	if isolated {
+		logInfo("🌐 Le moniteur semble isolé du réseau : au moins %.0f%% des sites sont en panne", isolationRatio*100)
+	} else {
+		logInfo("🌐 Connectivité du moniteur rétablie")
+	}
+	return true
+}
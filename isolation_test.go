@@ -0,0 +1,42 @@
+package main
+
+import "testing"
+
+func TestDetectIsolation(t *testing.T) {
+	up := SiteStatus{IsUp: true, State: stateUp}
+	down := SiteStatus{State: stateDown}
+	pending := SiteStatus{State: statePending}
+	paused := SiteStatus{State: statePaused}
+	redirected := SiteStatus{State: stateRedirected}
+
+	tests := []struct {
+		name     string
+		ratio    float64
+		statuses []SiteStatus
+		want     bool
+	}{
+		{"tous en panne", 1, []SiteStatus{down, down, down}, true},
+		{"un site répond", 1, []SiteStatus{down, down, up}, false},
+		{"ratio partiel atteint", 0.6, []SiteStatus{down, down, up}, true},
+		{"ratio partiel non atteint", 0.8, []SiteStatus{down, down, up}, false},
+		{"détection désactivée", 0, []SiteStatus{down, down, down}, false},
+		{"en attente ignorés", 1, []SiteStatus{down, pending, pending, pending}, false},
+		{"en pause ignorés", 1, []SiteStatus{down, paused, paused, paused}, false},
+		{"redirigés ignorés", 0.6, []SiteStatus{down, up, up, redirected, redirected, redirected}, false},
+		{"redirigés hors du ratio", 1, []SiteStatus{down, down, down, redirected}, true},
+		{"trop peu de sites", 1, []SiteStatus{down, down}, false},
+	}
+
+	oldRatio, oldMin := isolationRatio, isolationMinSites
+	defer func() { isolationRatio, isolationMinSites = oldRatio, oldMin }()
+	isolationMinSites = defaultIsolationMinSites
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			isolationRatio = tt.ratio
+			if got := detectIsolation(tt.statuses); got != tt.want {
+				t.Errorf("detectIsolation() = %v, attendu %v", got, tt.want)
+			}
+		})
+	}
+}
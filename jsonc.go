@@ -0,0 +1,51 @@
+package main
+
+// stripJSONComments retire les commentaires // et /* */ d'un document JSONC.
+// Le contenu des chaînes est préservé ; les retours à la ligne des commentaires
+// sont conservés pour que les positions d'erreur de json.Unmarshal restent parlantes.
+func stripJSONComments(data []byte) []byte {
+	out := make([]byte, 0, len(data))
+	inString := false
+
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+
+		if inString {
+			out = append(out, c)
+			if c == '\\' && i+1 < len(data) {
+				i++
+				out = append(out, data[i])
+			} else if c == '"' {
+				inString = false
+			}
+			continue
+		}
+
+		switch {
+		case c == '"':
+			inString = true
+			out = append(out, c)
+		case c == '/' && i+1 < len(data) && data[i+1] == '/':
+			// Commentaire ligne : on saute jusqu'au retour à la ligne
+			for i < len(data) && data[i] != '\n' {
+				i++
+			}
+			if i < len(data) {
+				out = append(out, '\n')
+			}
+		case c == '/' && i+1 < len(data) && data[i+1] == '*':
+			// Commentaire bloc : on saute jusqu'à */ en gardant les retours à la ligne
+			i += 2
+			for i < len(data) && !(data[i] == '*' && i+1 < len(data) && data[i+1] == '/') {
+				if data[i] == '\n' {
+					out = append(out, '\n')
+				}
+				i++
+			}
+			i++ // saute le '/' final
+		default:
+			out = append(out, c)
+		}
+	}
+	return out
+}
@@ -0,0 +1,26 @@
+package main
+
+import "testing"
+
+func TestStripJSONComments(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"sans commentaire", `{"a": 1}`, `{"a": 1}`},
+		{"ligne", "{\"a\": 1 // commentaire\n}", "{\"a\": 1 \n}"},
+		{"ligne en fin de fichier", `{"a": 1} // fin`, `{"a": 1} `},
+		{"bloc", `{"a": /* commentaire */ 1}`, `{"a":  1}`},
+		{"bloc multiligne garde les retours", "{/* un\ndeux\n*/\"a\": 1}", "{\n\n\"a\": 1}"},
+		{"URL dans une chaîne", `{"url": "http://example.com"}`, `{"url": "http://example.com"}`},
+		{"bloc dans une chaîne", `{"a": "/* pas un commentaire */"}`, `{"a": "/* pas un commentaire */"}`},
+		{"guillemet échappé", `{"a": "x\" // y"} // z`, `{"a": "x\" // y"} `},
+		{"bloc non fermé", `{"a": 1} /* ouvert`, `{"a": 1} `},
+	}
+	for _, tt := range tests {
+		if got := string(stripJSONComments([]byte(tt.in))); got != tt.want {
+			t.Errorf("%s : stripJSONComments(%q) = %q, attendu %q", tt.name, tt.in, got, tt.want)
+		}
+	}
+}
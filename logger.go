@@ -0,0 +1,104 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+)
+
+// Journalisation : format lisible (emojis) par défaut, ou une ligne JSON par événement
+// avec LOG_FORMAT=json pour les agrégateurs de logs. Tous les messages passent par ici
+// pour que les deux formats restent alignés.
+var (
+	logJSON = os.Getenv("LOG_FORMAT") == "json"
+	jsonLog = log.New(os.Stderr, "", 0)
+)
+
+// logFields sont les champs structurés ajoutés à une ligne JSON (ignorés en format lisible)
+type logFields map[string]any
+
+func logInfo(format string, args ...any)  { logEvent("info", nil, format, args...) }
+func logWarn(format string, args ...any)  { logEvent("warn", nil, format, args...) }
+func logError(format string, args ...any) { logEvent("error", nil, format, args...) }
+
+// logFatal journalise puis arrête le processus, comme log.Fatalf
+func logFatal(format string, args ...any) {
+	logEvent("fatal", nil, format, args...)
+	os.Exit(1)
+}
+
+// logEvent écrit un message au niveau donné, avec des champs structurés facultatifs
+func logEvent(level string, fields logFields, format string, args ...any) {
+	msg := strings.TrimSuffix(fmt.Sprintf(format, args...), "\n")
+	if !logJSON {
+		log.Print(msg)
+		return
+	}
+
+	entry := make(map[string]any, len(fields)+3)
+	for k, v := range fields {
+		entry[k] = v
+	}
+	entry["ts"] = time.Now().UTC().Format(time.RFC3339Nano)
+	entry["level"] = level
+	entry["msg"] = msg
+	line, err := json.Marshal(entry)
+	if err != nil {
+		line, _ = json.Marshal(map[string]string{"level": level, "msg": msg})
+	}
+	jsonLog.Print(string(line))
+}
+
+// siteFields identifie le site concerné par un événement
+func siteFields(s Site) logFields {
+	return logFields{"site_id": s.ID}
+}
+
+// statusFields décrit le résultat d'une vérification
+func statusFields(st SiteStatus) logFields {
+	f := logFields{
+		"site_id":          st.Site.ID,
+		"state":            st.State,
+		"is_up":            st.IsUp,
+		"response_time_ms": st.ResponseTime,
+		"status_code":      st.StatusCode,
+		"in_maintenance":   st.Maintenance,
+	}
+	if st.Error != "" {
+		f["error"] = st.Error
+	}
+	return f
+}
+
+// logCheck journalise le résultat d'une vérification
+func logCheck(st SiteStatus) {
+	if logJSON {
+		level := "info"
+		if st.State != stateUp {
+			level = "warn"
+		}
+		logEvent(level, statusFields(st), "Vérification de %s", st.Site.Name)
+		return
+	}
+
+	icon := "✅"
+	switch {
+	case st.Maintenance:
+		icon = "🔧"
+	case !st.IsUp:
+		icon = "❌"
+	case st.State == stateDegraded:
+		icon = "🟡"
+	}
+	log.Printf("   %s %-20s → %4dms (code %d) [%s] %s",
+		icon,
+		st.Site.Name,
+		st.ResponseTime,
+		st.StatusCode,
+		st.LastChecked.Format("15:04:05"),
+		st.Error,
+	)
+}
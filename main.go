@@ -1,32 +1,281 @@
 package main
 
 import (
+	"cmp"
 	"context"
+	"crypto/tls"
 	"encoding/json"
-	"log"
+	"errors"
+	"fmt"
+	"io"
+	"net"
 	"net/http"
+	"net/http/httptrace"
+	"net/url"
 	"os"
 	"os/signal"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
 	"sync"
 	"syscall"
 	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
-// Site représente un site à surveiller
+// Site représente un site à surveiller. Ses identifiants (mot de passe, en-têtes d'authentification,
+// user:pass@ de l'URL ou du proxy) sont masqués dans toute sortie JSON, voir redactSite.
 type Site struct {
 	ID   string `json:"id"`
 	Name string `json:"name"`
 	URL  string `json:"url"`
+
+	// Maintenance plages pendant lesquelles le site reste vérifié mais sans alerte ni incident
+	Maintenance []MaintenanceWindow `json:"maintenance,omitempty"`
+
+	// DegradedMs seuil de temps de réponse au-delà duquel un site qui répond est "degraded" ; 0 désactive
+	DegradedMs int64 `json:"degraded_ms,omitempty"`
+
+	// SLAResponseMs temps de réponse garanti, suivi par /api/sla sur l'historique conservé ; 0 : pas de SLA
+	SLAResponseMs int64 `json:"sla_response_ms,omitempty"`
+
+	// HistoryRetention remplace pour ce site la rétention globale de l'historique : un nombre de
+	// vérifications ("5000") ou une durée ("1h", "7d"). Vide : HISTORY_SIZE et HISTORY_RETENTION_DAYS.
+	HistoryRetention string `json:"history_retention,omitempty"`
+
+	// Type de vérification : "http" (par défaut) ou "tcp", auquel cas URL est une adresse
+	// host:port (préfixe tcp:// accepté) dont on vérifie seulement qu'elle accepte une connexion,
+	// ou "grpc" : URL est une cible host:port (grpc:// en clair, grpcs:// en TLS) interrogée
+	// via le protocole standard grpc.health.v1
+	Type string `json:"type,omitempty"`
+
+	// Endpoints URL vérifiées ensemble à la place d'URL (facultative dans ce cas) : le site n'est up
+	// que si toutes le sont, avec les mêmes options (timeout, assertions, en-têtes...)
+	Endpoints []string `json:"endpoints,omitempty"`
+
+	// GRPCService sous-service à interroger pour un site grpc ; vide : santé globale du serveur
+	GRPCService string `json:"grpc_service,omitempty"`
+
+	// IntervalSeconds fixe la cadence propre au site ; 0 ou absent : intervalle global
+	IntervalSeconds int `json:"interval_seconds,omitempty"`
+
+	// Priority ordre de lancement dans une passe : les sites de priorité plus élevée partent
+	// en premier quand MAX_CONCURRENCY limite le parallélisme (0 par défaut, ordre du fichier)
+	Priority int `json:"priority,omitempty"`
+
+	// TimeoutSeconds délai maximal d'une vérification ; 0 ou absent : 10 secondes
+	TimeoutSeconds int `json:"timeout_seconds,omitempty"`
+
+	// RetryCount nombre de nouvelles tentatives (backoff 200ms, 400ms, 800ms...) après une erreur
+	// de connexion ou un 5xx, avant de déclarer le site en panne ; 0 par défaut
+	RetryCount int `json:"retry_count,omitempty"`
+
+	// Contexte destiné à l'astreinte, renvoyé tel quel dans le statut
+	Description string `json:"description,omitempty"`
+	Runbook     string `json:"runbook,omitempty"`
+
+	// IdleTimeoutSeconds limite la durée de vie des connexions inactives vers ce site.
+	// Utile pour ne pas garder de sockets ouverts vers des sites rarement vérifiés ;
+	// 0 utilise les réglages du transport partagé.
+	IdleTimeoutSeconds int `json:"idle_timeout_seconds,omitempty"`
+
+	// ForceHTTP1 désactive HTTP/2 pour les serveurs dont l'implémentation est défaillante
+	ForceHTTP1 bool `json:"force_http1,omitempty"`
+
+	// DisableKeepAlive impose une nouvelle connexion à chaque vérification,
+	// pour exercer tous les backends derrière un load balancer
+	DisableKeepAlive bool `json:"disable_keepalive,omitempty"`
+
+	// SourceIP force l'adresse locale utilisée pour sortir (hôte multi-homé)
+	SourceIP string `json:"source_ip,omitempty"`
+
+	// Network impose la famille d'adresses : "tcp4", "tcp6" ou "tcp" (par défaut, les deux)
+	Network string `json:"network,omitempty"`
+
+	// RequireNonEmptyBody marque le site en panne s'il répond avec succès mais un corps vide
+	RequireNonEmptyBody bool `json:"require_non_empty_body,omitempty"`
+
+	// ExpectKeywords liste des chaînes recherchées dans le corps ; KeywordMode vaut
+	// "all" (toutes présentes, par défaut) ou "any" (au moins une)
+	ExpectKeywords []string `json:"expect_keywords,omitempty"`
+	KeywordMode    string   `json:"keyword_mode,omitempty"`
+
+	// MustContain texte qui doit toujours figurer dans le corps, quel que soit KeywordMode
+	// (utile pour repérer une page d'erreur servie avec un 200)
+	MustContain string `json:"must_contain,omitempty"`
+
+	// MustNotContain texte interdit : présent dans le corps, il met le site en panne même avec un 2xx
+	// (ex. "Service Unavailable" rendu dans une page 200)
+	MustNotContain string `json:"must_not_contain,omitempty"`
+
+	// WatchContent calcule une empreinte du corps (borné par MAX_BODY_BYTES) à chaque vérification
+	// et signale tout changement par rapport à la précédente (défiguration, mauvais build déployé)
+	WatchContent bool `json:"watch_content,omitempty"`
+
+	// MinHTTPVersion (ex. "2" ou "1.1") : une version négociée inférieure met le site en panne
+	MinHTTPVersion string `json:"min_http_version,omitempty"`
+
+	// SchemaFile chemin d'un JSON Schema contre lequel valider le corps de la réponse
+	SchemaFile string `json:"schema_file,omitempty"`
+
+	// FlagRedirects ne suit pas les redirections et signale un 3xx par l'état "redirected"
+	// au lieu de le compter comme "up"
+	FlagRedirects bool `json:"flag_redirects,omitempty"`
+
+	// FollowRedirects (vrai par défaut) : à false, le premier 3xx est évalué tel quel
+	// (règles de code habituelles) au lieu d'être suivi
+	FollowRedirects *bool `json:"follow_redirects,omitempty"`
+
+	// ExpectedStatus code HTTP exact attendu (ex. 401 pour une API protégée) ;
+	// 0 ou absent : tout code 200–399 est considéré comme up
+	ExpectedStatus int `json:"expected_status,omitempty"`
+
+	// AcceptableStatus liste des codes HTTP considérés comme up (ex. [200, 204, 302]) ;
+	// prime sur ExpectedStatus et sur la règle 200–399
+	AcceptableStatus []int `json:"acceptable_status,omitempty"`
+
+	// Method méthode HTTP de la vérification : GET (par défaut), HEAD, POST ou PUT.
+	// Body est envoyé tel quel avec un POST ou un PUT, avec le type BodyContentType (application/json par défaut).
+	Method          string `json:"method,omitempty"`
+	Body            string `json:"body,omitempty"`
+	BodyContentType string `json:"body_content_type,omitempty"`
+
+	// Headers en-têtes ajoutés à la requête, envoyés tels quels (valeurs non échappées ni
+	// interprétées). Ils priment sur les en-têtes posés par défaut ; "Host" remplace l'hôte virtuel.
+	// Les valeurs des en-têtes d'authentification (Authorization, X-API-Key...) sont masquées en sortie.
+	Headers map[string]string `json:"headers,omitempty"`
+
+	// UserAgent remplace pour ce site le User-Agent global (USER_AGENT, site-monitor/1.0 par défaut)
+	UserAgent string `json:"user_agent,omitempty"`
+
+	// Proxy URL d'un proxy http(s):// ou socks5:// propre au site ; sinon HTTP_PROXY/HTTPS_PROXY.
+	// Son éventuel mot de passe (user:pass@) est masqué en sortie.
+	Proxy string `json:"proxy,omitempty"`
+
+	// Username et Password identifiants HTTP Basic envoyés quand les deux sont définis.
+	// Le mot de passe est masqué dans toutes les réponses de l'API.
+	Username string `json:"username,omitempty"`
+	Password secret `json:"password,omitempty"`
+
+	// Group équipe ou ensemble auquel appartient le site (filtre ?group= de /api/status) ;
+	// un site sans groupe appartient au groupe "ungrouped"
+	Group string `json:"group,omitempty"`
+
+	// CaptureHeaders noms des en-têtes de réponse à recopier dans le statut (ResponseHeaders),
+	// pour le débogage ; sans effet sur l'état du site
+	CaptureHeaders []string `json:"capture_headers,omitempty"`
+
+	// ConfirmDown relance aussitôt une vérification distincte après un échec ; le site n'est
+	// déclaré en panne que si elle échoue aussi
+	ConfirmDown bool `json:"confirm_down,omitempty"`
+
+	// Paused suspend la vérification du site (POST /api/sites/{id}/pause et /resume) ;
+	// son statut passe à l'état "paused" avec le dernier résultat figé
+	Paused bool `json:"paused,omitempty"`
+}
+
+// ungroupedGroup désigne les sites sans Group
+const ungroupedGroup = "ungrouped"
+
+// siteGroup renvoie le groupe d'un site, ungroupedGroup s'il n'en a pas
+func siteGroup(s Site) string {
+	if s.Group == "" {
+		return ungroupedGroup
+	}
+	return s.Group
 }
 
+// États possibles d'un site ; IsUp n'est vrai que pour stateUp et stateDegraded
+// (le site répond, mais lentement)
+const (
+	statePending    = "pending"
+	stateUp         = "up"
+	stateDegraded   = "degraded"
+	stateDown       = "down"
+	stateRedirected = "redirected"
+)
+
 // SiteStatus contient le statut d’un site après vérification
 type SiteStatus struct {
-	Site         Site      `json:"site"`
-	IsUp         bool      `json:"is_up"`
-	ResponseTime int64     `json:"response_time_ms"`
-	StatusCode   int       `json:"status_code"`
-	LastChecked  time.Time `json:"last_checked"`
-	Error        string    `json:"error,omitempty"`
+	Site           Site      `json:"site"`
+	IsUp           bool      `json:"is_up"`
+	State          string    `json:"state"`
+	ResponseTime   int64     `json:"response_time_ms"`
+	ResponseTimeUs int64     `json:"response_time_us"` // même mesure, en microsecondes
+	StatusCode     int       `json:"status_code"`
+	LastChecked    time.Time `json:"last_checked"`
+	Error          string    `json:"error,omitempty"`
+	Protocol       string    `json:"protocol,omitempty"`       // protocole négocié, ex. "HTTP/2.0"
+	Location       string    `json:"location,omitempty"`       // cible d'une redirection non suivie
+	Redirects      int       `json:"redirects,omitempty"`      // redirections suivies
+	FinalURL       string    `json:"final_url,omitempty"`      // URL ayant produit la réponse, après redirections
+	SourceAddr     string    `json:"source_addr,omitempty"`    // adresse locale utilisée si SourceIP est défini
+	RemoteAddr     string    `json:"remote_addr,omitempty"`    // IP:port ayant servi la réponse (le proxy s'il y en a un)
+	BytesRead      int64     `json:"bytes_read"`               // octets du corps effectivement lus
+	BodyLength     *int64    `json:"body_length,omitempty"`    // renseigné quand le corps est inspecté
+	BodyTruncated  bool      `json:"body_truncated,omitempty"` // corps inspecté au-delà de MAX_BODY_BYTES
+
+	// Suivi du contenu (Site.WatchContent) : empreinte SHA-256 du dernier corps accepté et validateurs
+	// HTTP. ContentChanged est vrai quand l'empreinte diffère de la précédente empreinte connue.
+	ContentHash    string `json:"content_hash,omitempty"`
+	ContentChanged bool   `json:"content_changed,omitempty"`
+	ETag           string `json:"etag,omitempty"`
+	LastModified   string `json:"last_modified,omitempty"`
+
+	// Endpoints résultat de chaque URL d'un site multi-endpoints
+	Endpoints []EndpointStatus `json:"endpoints,omitempty"`
+
+	// PayloadAccepted indique, quand Site.Body a été envoyé, si le code de réponse satisfait
+	// les règles de code du site (le serveur a accepté la charge utile)
+	PayloadAccepted *bool `json:"payload_accepted,omitempty"`
+
+	// Horodatages bruts pour corréler avec les logs d'accès du site cible
+	RequestStartedAt   time.Time  `json:"request_started_at"`
+	ResponseReceivedAt *time.Time `json:"response_received_at,omitempty"`
+
+	// Vérifications en échec d'affilée (remis à 0 dès que le site répond, non compté en maintenance)
+	ConsecutiveFailures int `json:"consecutive_failures"`
+
+	// ConfirmedDown vrai si la panne a été confirmée par une seconde vérification (Site.ConfirmDown) ;
+	// TransientBlips compte les échecs démentis par la vérification de confirmation
+	ConfirmedDown  bool `json:"confirmed_down,omitempty"`
+	TransientBlips int  `json:"transient_blips,omitempty"`
+
+	// Flapping vrai si le site change trop souvent d'état (voir flap.go)
+	Flapping bool `json:"flapping"`
+
+	// BackoffSeconds intervalle allongé appliqué au site tant qu'il reste en échec (voir backoff.go)
+	BackoffSeconds int64 `json:"backoff_interval_seconds,omitempty"`
+
+	// Maintenance vrai si la vérification a eu lieu pendant une plage de maintenance du site
+	Maintenance bool `json:"in_maintenance"`
+
+	// Expiration la plus proche de la chaîne de certificats (sites HTTPS uniquement)
+	CertExpiry   *time.Time `json:"cert_expiry,omitempty"`
+	CertDaysLeft *int       `json:"cert_days_left,omitempty"`
+
+	// Décomposition de la latence (httptrace), renseignée seulement si la connexion a abouti.
+	// DNS, connexion et TLS sont absents quand une connexion existante a été réutilisée.
+	DNSMs     *int64 `json:"dns_ms,omitempty"`
+	ConnectMs *int64 `json:"connect_ms,omitempty"`
+	TLSMs     *int64 `json:"tls_ms,omitempty"`
+	TTFBMs    *int64 `json:"ttfb_ms,omitempty"`
+
+	// ErrorType catégorie de l'échec (dns, timeout, connection_refused, tls, http_status, other),
+	// en complément du message lisible d'Error
+	ErrorType string `json:"error_type,omitempty"`
+
+	// ResponseHeaders valeurs des en-têtes listés dans Site.CaptureHeaders et présents dans la réponse
+	ResponseHeaders map[string]string `json:"response_headers,omitempty"`
+
+	// warmup marque un résultat obtenu pendant la période de démarrage (alertes suspendues)
+	warmup bool
+
+	// pausedFrom conserve l'état d'avant la pause, rétabli à la reprise
+	pausedFrom string
 }
 
 var (
@@ -34,36 +283,160 @@ var (
 	statuses    []SiteStatus
 	statusMutex sync.RWMutex
 	startTime   = time.Now()
+
+	// passMutex garantit qu'une seule passe complète de vérification tourne à la fois
+	passMutex sync.Mutex
+
+	// En-têtes de cache appliqués aux endpoints de statut
+	statusCacheSeconds int
+	statusCacheControl string
 )
 
+// defaultPort est utilisé quand PORT n'est pas défini (développement local)
+const defaultPort = "8080"
+
 func main() {
+	// Options de ligne de commande, prioritaires sur l'environnement
+	parseFlags()
+
 	// 1. Charger la configuration des sites
-	if err := loadSites("config/sites.json"); err != nil {
-		log.Fatalf("❌ Impossible de charger les sites : %v", err)
+	if err := loadSites(sitesConfigPath); err != nil {
+		logFatal("❌ Impossible de charger les sites : %v", err)
+	}
+	if len(sites) == 0 {
+		logWarn("⚠️ Aucun site dans %s : vérifier SITES_CONFIG ou ajouter des sites via l'API", sitesConfigPath)
+	} else {
+		logInfo("✅ %d site(s) à surveiller (%s)\n", len(sites), sitesConfigPath)
 	}
-	log.Printf("✅ %d site(s) à surveiller\n", len(sites))
+
+	// Intervalle global de vérification
+	loadIntervalConfig()
+
+	// Taille de l'historique conservé par site
+	loadHistoryConfig()
+	if err := openHistoryDB(); err != nil {
+		logFatal("❌ Impossible d'ouvrir la base d'historique : %v", err)
+	}
+	defer closeHistoryDB()
+	if err := openAuditLog(); err != nil {
+		logFatal("❌ Impossible d'ouvrir le journal d'audit : %v", err)
+	}
+	defer closeAuditLog()
+	loadIncidentConfig()
+	loadRetentionConfig()
+
+	// Configuration du cache HTTP des endpoints de statut
+	loadCacheConfig()
+
+	// Transport HTTP partagé par les vérifications
+	loadProxyConfig()
+	initTransport()
+	loadConcurrencyConfig()
+	loadBodyConfig()
+	loadShutdownConfig()
+	loadHeartbeatConfig()
+	loadStatusPolicyConfig()
+	loadCORSConfig()
+
+	// Seuil de détection d'un moniteur isolé du réseau
+	loadIsolationConfig()
+	loadBackoffConfig()
+	loadFlapConfig()
+
+	// Canaux de notification des changements d'état
+	loadNotifyConfig()
+	loadEscalationConfig()
+	loadAlertThresholdConfig()
+	loadWarmupConfig()
+	loadEmailConfig()
+	loadCertConfig()
+
+	// Auto-test réseau facultatif avant de se déclarer prêt
+	runStartupCanary()
 
 	// 2. Initialiser le slice des statuses avec des valeurs par défaut
 	initializeEmptyStatuses()
+	restoreStatuses()
+	logSiteIntervals()
 
 	// 3. Démarrer le monitoring en arrière-plan
 	ctx, cancel := context.WithCancel(context.Background())
-	go startMonitoring(ctx)
+	shutdownTracing := initTracing(ctx)
+	var monitorWG sync.WaitGroup
+	monitorWG.Add(5)
+	go func() {
+		defer monitorWG.Done()
+		startMonitoring(ctx)
+	}()
+	go func() {
+		defer monitorWG.Done()
+		watchSitesConfig(ctx, sitesConfigPath)
+	}()
+	go func() {
+		defer monitorWG.Done()
+		watchSIGHUP(ctx, sitesConfigPath)
+	}()
+	go func() {
+		defer monitorWG.Done()
+		runHeartbeat(ctx)
+	}()
+	go func() {
+		defer monitorWG.Done()
+		runRetention(ctx)
+	}()
+	monitorDone := make(chan struct{})
+	go func() {
+		monitorWG.Wait()
+		close(monitorDone)
+	}()
 
 	// 4. Construire le ServeMux et ajouter les handlers
 	mux := http.NewServeMux()
-	mux.HandleFunc("/api/sites", recoveryMiddleware(handleSites))
+	mux.HandleFunc("GET /{$}", recoveryMiddleware(handleDashboard))
+	mux.HandleFunc("GET /dashboard", recoveryMiddleware(handleDashboard))
+	mux.HandleFunc("GET /api/sites", recoveryMiddleware(handleSites))
+	mux.HandleFunc("POST /api/sites", recoveryMiddleware(handleCreateSite))
+	mux.HandleFunc("GET /api/sites/{id}", recoveryMiddleware(handleSiteByID))
+	mux.HandleFunc("PUT /api/sites/{id}", recoveryMiddleware(handleUpdateSite))
+	mux.HandleFunc("DELETE /api/sites/{id}", recoveryMiddleware(handleDeleteSite))
+	mux.HandleFunc("POST /api/sites/{id}/pause", recoveryMiddleware(handlePauseSite))
+	mux.HandleFunc("POST /api/sites/{id}/resume", recoveryMiddleware(handleResumeSite))
 	mux.HandleFunc("/api/status", recoveryMiddleware(handleStatus))
+	mux.HandleFunc("/api/uptime", recoveryMiddleware(handleUptime))
+	mux.HandleFunc("GET /api/sla", recoveryMiddleware(handleSLA))
+	mux.HandleFunc("GET /api/history", recoveryMiddleware(handleHistory))
+	mux.HandleFunc("/api/stats", recoveryMiddleware(handleStats))
+	mux.HandleFunc("GET /api/histogram", recoveryMiddleware(handleHistogram))
+	mux.HandleFunc("GET /api/groups", recoveryMiddleware(handleGroups))
+	mux.HandleFunc("GET /api/incidents", recoveryMiddleware(handleIncidents))
+	mux.HandleFunc("GET /api/stream", recoveryMiddleware(handleStream))
+	mux.HandleFunc("GET /api/export.csv", recoveryMiddleware(handleExportCSV))
+	mux.HandleFunc("GET /api/badge/{file}", recoveryMiddleware(handleBadge))
 	mux.HandleFunc("/api/health", recoveryMiddleware(handleHealth))
+	mux.HandleFunc("GET /api/health/live", recoveryMiddleware(handleLive))
+	mux.HandleFunc("GET /api/health/ready", recoveryMiddleware(handleReady))
+	mux.HandleFunc("/api/ping", handlePing)
+	mux.HandleFunc("GET /api/config", recoveryMiddleware(handleGetConfig))
+	mux.HandleFunc("PUT /api/config", recoveryMiddleware(handlePutConfig))
+	mux.HandleFunc("GET /api/audit", recoveryMiddleware(handleAudit))
+	mux.HandleFunc("GET /api/schedule", recoveryMiddleware(handleSchedule))
+	mux.HandleFunc("POST /api/config/interval", recoveryMiddleware(handleSetInterval))
+	mux.HandleFunc("POST /api/check", recoveryMiddleware(handleCheckAll))
+	mux.HandleFunc("POST /api/check/{id}", recoveryMiddleware(handleCheckSite))
+	mux.HandleFunc("POST /api/diagnose/{id}", recoveryMiddleware(handleDiagnose))
+	mux.HandleFunc("GET /api/metrics", recoveryMiddleware(handleMetricsJSON))
+	mux.HandleFunc("/metrics", recoveryMiddleware(handleMetrics))
 
-	// 5. Envelopper dans le middleware CORS
-	handlerWithCORS := corsMiddleware(mux)
+	// 5. Envelopper dans les middlewares de limitation de débit, d’authentification et CORS
+	handlerWithCORS := corsMiddleware(rateLimitMiddleware(authMiddleware(mux)))
 
-	// 6. Récupérer le port depuis l'environnement
+	// 6. Récupérer le port (option -port ou environnement)
 	port := os.Getenv("PORT")
 	if port == "" {
-		log.Fatal("La variable d’environnement PORT n’est pas définie")
+		port = defaultPort
+		logInfo("ℹ️ PORT non défini, port par défaut %s utilisé", port)
 	}
+	logEffectiveConfig(port)
 
 	// 7. Configurer le serveur HTTP avec timeouts
 	srv := &http.Server{
@@ -73,173 +446,1063 @@ func main() {
 		WriteTimeout: 10 * time.Second,
 		IdleTimeout:  60 * time.Second,
 	}
+	srv.RegisterOnShutdown(stopStreams)
 
-	// 8. Démarrer le serveur dans une goroutine
+	// 8. Ouvrir le port tout de suite pour signaler clairement un port occupé,
+	// puis servir dans une goroutine
+	ln, err := net.Listen("tcp", srv.Addr)
+	if errors.Is(err, syscall.EADDRINUSE) {
+		logFatal("❌ Le port %s est déjà utilisé par un autre processus (choisir un autre PORT)", port)
+	}
+	if err != nil {
+		logFatal("❌ Impossible d’écouter sur le port %s : %v", port, err)
+	}
 	go func() {
-		log.Printf("🚀 Site Monitor API démarrée sur le port %s", port)
-		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatalf("Le serveur HTTP s’est arrêté de manière inattendue : %v", err)
+		logInfo("🚀 Site Monitor API démarrée sur le port %s", port)
+		if err := srv.Serve(ln); err != nil && err != http.ErrServerClosed {
+			logFatal("Le serveur HTTP s’est arrêté de manière inattendue : %v", err)
 		}
 	}()
 
+	// Serveur gRPC facultatif, sur le même état que l'API HTTP
+	grpcServer := startGRPCServer()
+
 	// 9. Attendre un signal d’arrêt (Ctrl+C, SIGINT, SIGTERM)
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
-	log.Println("🔔 Signal d'arrêt reçu, arrêt propre du serveur...")
+	logInfo("🔔 Signal d'arrêt reçu, arrêt propre du serveur...")
 
 	// 10. Annuler le contexte du monitoring
 	cancel()
 
-	// 11. Shutdown du serveur avec un timeout de 5 secondes
-	ctxShutdown, cancelShutdown := context.WithTimeout(context.Background(), 5*time.Second)
+	// 11. Shutdown du serveur avec un timeout (SHUTDOWN_TIMEOUT_SECONDS), après la fin de la passe en cours
+	// et des notifications qu'elle a déclenchées
+	ctxShutdown, cancelShutdown := context.WithTimeout(context.Background(), shutdownTimeout)
 	defer cancelShutdown()
-	if err := srv.Shutdown(ctxShutdown); err != nil {
-		log.Fatalf("🛑 Erreur lors de l’arrêt du serveur : %v", err)
+	var pending []string
+	select {
+	case <-monitorDone:
+	case <-ctxShutdown.Done():
+		pending = append(pending, pendingPass())
+	}
+	// Les dernières transitions de la passe ont pu lancer des alertes : les laisser partir
+	if !waitNotifications(ctxShutdown) {
+		pending = append(pending, pendingNotifications())
+	}
+	if grpcServer != nil && !stopGRPCServer(ctxShutdown, grpcServer) {
+		pending = append(pending, "appels gRPC")
+	}
+	shutdownTracing(ctxShutdown)
+	saveStatuses()
+	err = srv.Shutdown(ctxShutdown)
+	if errors.Is(err, context.DeadlineExceeded) {
+		pending = append(pending, "requêtes HTTP")
+		srv.Close()
+	} else if err != nil {
+		logFatal("🛑 Erreur lors de l’arrêt du serveur : %v", err)
 	}
-	log.Println("✅ Serveur arrêté proprement")
+	if len(pending) > 0 {
+		logPendingShutdown(pending)
+		return
+	}
+	logInfo("✅ Serveur arrêté proprement")
 }
 
-// loadSites lit le fichier JSON et remplit le slice sites
+// loadSites lit le fichier JSON (commentaires JSONC acceptés, overlay ENV éventuel)
+// et remplit le slice sites
 func loadSites(filepath string) error {
-	data, err := os.ReadFile(filepath)
+	list, err := parseSites(filepath)
+	if err != nil {
+		return err
+	}
+	sites = list
+	return nil
+}
+
+// parseSites lit et valide la configuration des sites sans toucher à l'état courant
+func parseSites(filepath string) ([]Site, error) {
+	data, err := readSitesConfig(filepath)
 	if err != nil {
+		return nil, err
+	}
+	var list []Site
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, err
+	}
+	if err := validateSites(list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// validateSites vérifie toute la configuration et liste chaque problème trouvé,
+// pour pouvoir tout corriger en une fois
+func validateSites(list []Site) error {
+	var problems []string
+	seen := make(map[string]int, len(list))
+	for i, s := range list {
+		label := fmt.Sprintf("site #%d", i+1)
+		if s.ID != "" {
+			label += fmt.Sprintf(" (%s)", s.ID)
+			if first, dup := seen[s.ID]; dup {
+				problems = append(problems, fmt.Sprintf("%s : id déjà utilisé par le site #%d", label, first))
+			} else {
+				seen[s.ID] = i + 1
+			}
+		}
+		for _, p := range validateSite(s) {
+			problems = append(problems, fmt.Sprintf("%s : %s", label, p))
+		}
+	}
+	if len(problems) == 0 {
+		return nil
+	}
+	return fmt.Errorf("configuration invalide, %d problème(s) :\n  - %s", len(problems), strings.Join(problems, "\n  - "))
+}
+
+// validateSite renvoie les problèmes d'une définition de site (vide si elle est valide)
+func validateSite(s Site) []string {
+	var problems []string
+	if s.ID == "" {
+		problems = append(problems, "id requis")
+	}
+	if s.Name == "" {
+		problems = append(problems, "name requis")
+	}
+	problems = append(problems, validateEndpoints(s)...)
+	if s.URL == "" {
+		if len(s.Endpoints) == 0 {
+			problems = append(problems, "url requise")
+		}
+	} else if checkType(s) == checkTypeTCP {
+		if err := validateTCPTarget(s); err != nil {
+			problems = append(problems, err.Error())
+		}
+	} else if checkType(s) == checkTypeGRPC {
+		if err := validateGRPCTarget(s); err != nil {
+			problems = append(problems, err.Error())
+		}
+	} else if u, err := url.Parse(s.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		problems = append(problems, fmt.Sprintf("url %q invalide (http ou https attendu)", s.URL))
+	}
+	if err := validateSiteOptions(s); err != nil {
+		problems = append(problems, err.Error())
+	}
+	return problems
+}
+
+// validateSiteOptions vérifie les options facultatives d'un site
+func validateSiteOptions(s Site) error {
+	if _, ok := checkers[checkType(s)]; !ok {
+		return fmt.Errorf("type %q inconnu (attendu : http, tcp ou grpc)", s.Type)
+	}
+	if s.SourceIP != "" {
+		if err := checkSourceIP(s.SourceIP); err != nil {
+			return err
+		}
+	}
+	switch s.Network {
+	case "", "tcp", "tcp4", "tcp6":
+	default:
+		return fmt.Errorf("network %q inconnu (attendu : tcp, tcp4 ou tcp6)", s.Network)
+	}
+	if s.Proxy != "" {
+		if _, err := parseProxyURL(s.Proxy); err != nil {
+			return err
+		}
+	}
+	if s.MinHTTPVersion != "" {
+		if _, _, err := parseHTTPVersion(s.MinHTTPVersion); err != nil {
+			return err
+		}
+	}
+	if s.SchemaFile != "" {
+		if err := compileSchema(s.SchemaFile); err != nil {
+			return err
+		}
+	}
+	switch s.KeywordMode {
+	case "", "all", "any":
+	default:
+		return fmt.Errorf("keyword_mode %q invalide (attendu : all ou any)", s.KeywordMode)
+	}
+	if err := validateMaintenance(s); err != nil {
 		return err
 	}
-	if err := json.Unmarshal(data, &sites); err != nil {
+	if s.DegradedMs < 0 {
+		return fmt.Errorf("degraded_ms %d invalide", s.DegradedMs)
+	}
+	if (s.Username == "") != (s.Password == "") {
+		return fmt.Errorf("username et password doivent être définis ensemble")
+	}
+	if s.SLAResponseMs < 0 {
+		return fmt.Errorf("sla_response_ms %d invalide", s.SLAResponseMs)
+	}
+	if _, err := parseHistoryRetention(s.HistoryRetention); err != nil {
 		return err
 	}
+	if s.ExpectedStatus != 0 && (s.ExpectedStatus < 100 || s.ExpectedStatus > 599) {
+		return fmt.Errorf("expected_status %d invalide", s.ExpectedStatus)
+	}
+	for _, code := range s.AcceptableStatus {
+		if code < 100 || code > 599 {
+			return fmt.Errorf("acceptable_status : code %d invalide", code)
+		}
+	}
+	switch checkMethod(s) {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut:
+	default:
+		return fmt.Errorf("method %q invalide (attendu : GET, HEAD, POST ou PUT)", s.Method)
+	}
+	if (s.Body != "" || s.BodyContentType != "") && !methodAllowsBody(checkMethod(s)) {
+		return fmt.Errorf("body n'est accepté qu'avec les méthodes POST et PUT")
+	}
+	if checkMethod(s) == http.MethodHead && needsBody(s) {
+		return fmt.Errorf("la méthode HEAD ne renvoie pas de corps à inspecter")
+	}
 	return nil
 }
 
+// checkMethod renvoie la méthode HTTP de la vérification (GET par défaut)
+func checkMethod(site Site) string {
+	if site.Method == "" {
+		return http.MethodGet
+	}
+	return strings.ToUpper(site.Method)
+}
+
+// methodAllowsBody indique si une vérification avec cette méthode peut envoyer Site.Body
+func methodAllowsBody(method string) bool {
+	return method == http.MethodPost || method == http.MethodPut
+}
+
+// sendsBody indique si la vérification du site envoie une charge utile
+func sendsBody(site Site) bool {
+	return site.Body != "" && methodAllowsBody(checkMethod(site))
+}
+
+// newCheckRequest construit la requête de vérification selon la méthode du site
+func newCheckRequest(ctx context.Context, site Site) (*http.Request, error) {
+	method := checkMethod(site)
+	var body io.Reader
+	if sendsBody(site) {
+		body = strings.NewReader(site.Body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, site.URL, body)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		contentType := site.BodyContentType
+		if contentType == "" {
+			contentType = "application/json"
+		}
+		req.Header.Set("Content-Type", contentType)
+	}
+	ua := site.UserAgent
+	if ua == "" {
+		ua = userAgent
+	}
+	req.Header.Set("User-Agent", ua)
+	applyBasicAuth(req, site)
+	for name, value := range site.Headers {
+		if strings.EqualFold(name, "Host") {
+			req.Host = value
+			continue
+		}
+		req.Header.Set(name, value)
+	}
+	return req, nil
+}
+
 // initializeEmptyStatuses crée un slice de SiteStatus "vide" pour chaque site
 func initializeEmptyStatuses() {
 	statuses = make([]SiteStatus, len(sites))
 	now := time.Now()
 	for i, s := range sites {
-		statuses[i] = SiteStatus{
-			Site:         s,
-			IsUp:         false,
-			ResponseTime: 0,
-			StatusCode:   0,
-			LastChecked:  now,
-			Error:        "En attente de la première vérification",
+		statuses[i] = pendingStatus(s, now)
+	}
+}
+
+// pendingStatus renvoie le statut d'un site qui n'a pas encore été vérifié
+func pendingStatus(s Site, now time.Time) SiteStatus {
+	return syncPauseState(SiteStatus{
+		Site:         s,
+		IsUp:         false,
+		State:        statePending,
+		ResponseTime: 0,
+		StatusCode:   0,
+		LastChecked:  now,
+		Error:        "En attente de la première vérification",
+	})
+}
+
+// currentSites renvoie la liste des sites surveillés. Le slice n'est jamais modifié
+// en place (l'API le remplace), il peut donc être parcouru sans verrou.
+func currentSites() []Site {
+	statusMutex.RLock()
+	defer statusMutex.RUnlock()
+	return sites
+}
+
+// checkAllSites vérifie tous les sites (passe complète)
+func checkAllSites(ctx context.Context) {
+	checkSites(ctx, currentSites(), true)
+}
+
+// checkSites vérifie en parallèle les sites donnés et met à jour leur entrée dans statuses.
+// Le slice est remplacé (copie sur écriture) pour que les instantanés déjà diffusés restent intacts.
+// Si ctx est annulé, les vérifications déjà lancées vont à leur terme et seuls leurs résultats sont appliqués.
+// Renvoie les statuts de tous les sites à l'issue de la passe.
+// scheduled distingue les passes du planificateur des vérifications à la demande (API) :
+// seules les premières comptent pour la détection d'une surveillance figée (recordPass).
+func checkSites(ctx context.Context, list []Site, scheduled bool) []SiteStatus {
+	passMutex.Lock()
+	defer passMutex.Unlock()
+	return runPass(ctx, list, scheduled)
+}
+
+// tryCheckSites lance une passe à la demande comme checkSites si aucune autre ne se termine
+// au-delà de wait ; sinon renvoie false sans rien vérifier
+func tryCheckSites(ctx context.Context, list []Site, wait time.Duration) ([]SiteStatus, bool) {
+	deadline := time.Now().Add(wait)
+	for !passMutex.TryLock() {
+		if time.Now().After(deadline) || ctx.Err() != nil {
+			return nil, false
 		}
+		time.Sleep(50 * time.Millisecond)
 	}
+	defer passMutex.Unlock()
+	return runPass(ctx, list, false), true
 }
 
-// startMonitoring lance un ticker qui exécute checkAllSites toutes les 60 secondes
-func startMonitoring(ctx context.Context) {
-	// Première exécution immédiate
-	checkAllSites()
+// runPass effectue la passe de checkSites (passMutex détenu)
+func runPass(ctx context.Context, list []Site, scheduled bool) []SiteStatus {
+	list = activeSites(list)
 
-	ticker := time.NewTicker(60 * time.Second)
-	defer ticker.Stop()
+	ctx, span := tracer.Start(ctx, "check_pass", trace.WithAttributes(attribute.Int("check.sites", len(list))))
+	defer span.End()
 
-	for {
-		select {
-		case <-ctx.Done():
-			log.Println("🛑 Monitoring arrêté (contexte annulé)")
-			return
-		case t := <-ticker.C:
-			log.Printf("🔍 Nouvelle passe de vérification à %s\n", t.Format("2006-01-02 15:04:05"))
-			checkAllSites()
+	passStart := time.Now()
+	results := runChecks(ctx, list)
+	recordHistory(results)
+	persistHistory(results)
+
+	// Verrouiller pour remplacer l’ancien slice
+	statusMutex.Lock()
+	newStatuses := append([]SiteStatus(nil), statuses...)
+	index := make(map[string]int, len(newStatuses))
+	for i, st := range newStatuses {
+		index[st.Site.ID] = i
+	}
+	var changes []statusChange
+	for _, r := range results {
+		if i, ok := index[r.Site.ID]; ok {
+			prev := newStatuses[i]
+			// Site reconfiguré via l'API pendant la vérification : résultat obsolète
+			if !reflect.DeepEqual(prev.Site, r.Site) {
+				continue
+			}
+			// Le résultat frais vaut 1 si la vérification a démenti un échec, 0 sinon
+			r.TransientBlips += prev.TransientBlips
+			if !r.IsUp && !r.Maintenance {
+				r.ConsecutiveFailures = prev.ConsecutiveFailures + 1
+				if d := backoffInterval(effectiveInterval(r.Site), r.ConsecutiveFailures); d > 0 {
+					r.BackoffSeconds = int64(d / time.Second)
+					if prev.BackoffSeconds == 0 {
+						logEvent("info", siteFields(r.Site), "🐢 %s en échec depuis %d vérifications : cadence ralentie", r.Site.Name, r.ConsecutiveFailures)
+					}
+				}
+			}
+			r.Flapping = recordFlapState(r.Site.ID, r.State)
+			trackContentChange(prev, &r)
+			changes = append(changes, statusChange{Previous: prev, Current: r})
+			newStatuses[i] = r
 		}
 	}
+	statuses = newStatuses
+	isolated := detectIsolation(newStatuses)
+	isolationChanged := setIsolation(isolated)
+	statusMutex.Unlock()
+	publishStatuses(newStatuses)
+
+	if isolationChanged && !inWarmup(time.Now()) {
+		notifyIsolation(isolated)
+	}
+	trackIncidents(changes)
+	dispatchNotifications(changes, isolated)
+	dispatchEscalations(trackEscalations(changes), isolated)
+
+	// Une vérification manuelle ne doit pas masquer un planificateur bloqué
+	if scheduled {
+		recordPass(passStart)
+	}
+	return newStatuses
+}
+
+// maxConcurrency borne le nombre de vérifications simultanées (MAX_CONCURRENCY, 20 par défaut)
+// pour ne pas épuiser les ports éphémères avec des centaines de sites
+var maxConcurrency = 20
+
+// loadConcurrencyConfig lit MAX_CONCURRENCY
+func loadConcurrencyConfig() {
+	maxConcurrency = max(envInt("MAX_CONCURRENCY", 20), 1)
 }
 
-// checkAllSites parcourt tous les sites en parallèle et met à jour le slice statuses
-func checkAllSites() {
+// runChecks lance les vérifications en parallèle (au plus maxConcurrency à la fois), par priorité
+// décroissante, et renvoie les résultats dans l'ordre de list. Une fois ctx annulé, plus aucune
+// vérification n'est lancée : seuls les résultats des vérifications terminées sont renvoyés.
+func runChecks(ctx context.Context, list []Site) []SiteStatus {
 	var wg sync.WaitGroup
-	newStatuses := make([]SiteStatus, len(sites))
+	results := make([]SiteStatus, len(list))
+	completed := make([]bool, len(list))
+	sem := make(chan struct{}, maxConcurrency)
 
-	for i, site := range sites {
+launch:
+	for _, i := range dispatchOrder(list) {
+		site := list[i]
+		if ctx.Err() != nil {
+			break
+		}
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			break launch
+		}
 		wg.Add(1)
 		go func(idx int, s Site) {
 			defer wg.Done()
-			status := checkSite(s)
-			newStatuses[idx] = status
-
-			// Log synthétique
-			icon := "✅"
-			if !status.IsUp {
-				icon = "❌"
-			}
-			log.Printf("   %s %-20s → %4dms (code %d) [%s] %s",
-				icon,
-				s.Name,
-				status.ResponseTime,
-				status.StatusCode,
-				status.LastChecked.Format("15:04:05"),
-				status.Error,
-			)
+			defer func() { <-sem }()
+			status := checkSite(ctx, s)
+			status.Maintenance = inMaintenance(s, status.LastChecked)
+			status.warmup = inWarmup(status.LastChecked)
+			results[idx] = status
+			completed[idx] = true
+			logCheck(status)
+			recordCheck(status)
 		}(i, site)
 	}
 
 	wg.Wait()
+	done := results[:0]
+	for i, st := range results {
+		if completed[i] {
+			done = append(done, st)
+		}
+	}
+	return done
+}
 
-	// Verrouiller pour remplacer l’ancien slice
-	statusMutex.Lock()
-	statuses = newStatuses
-	statusMutex.Unlock()
+// dispatchOrder renvoie les indices de list par priorité décroissante, l'ordre d'origine
+// départageant les sites de même priorité
+func dispatchOrder(list []Site) []int {
+	order := make([]int, len(list))
+	for i := range order {
+		order[i] = i
+	}
+	slices.SortStableFunc(order, func(a, b int) int {
+		return cmp.Compare(list[b].Priority, list[a].Priority)
+	})
+	return order
+}
+
+// checkSite vérifie un site en réessayant si besoin (RetryCount) et renvoie le dernier résultat
+// Les nouvelles tentatives sont abandonnées si ctx est annulé (arrêt du serveur).
+func checkSite(ctx context.Context, site Site) (status SiteStatus) {
+	ctx, span := startCheckSpan(ctx, site)
+	defer func() { endCheckSpan(span, status) }()
+	beginCheck(site.ID)
+	defer endCheck(site.ID)
+
+	status = checkOnce(site)
+	attempts := 1
+	backoff := 200 * time.Millisecond
+	for attempts <= site.RetryCount && isRetryable(status) {
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return status
+		}
+		backoff *= 2
+		status = checkOnce(site)
+		attempts++
+	}
+	if attempts > 1 && !status.IsUp {
+		status.Error = fmt.Sprintf("%s (après %d tentatives)", failureMessage(status), attempts)
+	}
+	if site.ConfirmDown && !status.IsUp && ctx.Err() == nil {
+		status = confirmDown(site, status)
+	}
+	return status
+}
+
+// failureMessage décrit un échec, par le code HTTP à défaut de message d'erreur
+func failureMessage(st SiteStatus) string {
+	if st.Error != "" {
+		return st.Error
+	}
+	return fmt.Sprintf("code HTTP %d", st.StatusCode)
+}
+
+// confirmDown lance une vérification de confirmation après un échec. Son résultat, avec
+// ses propres mesures, remplace le premier : une confirmation réussie compte un échec passager.
+func confirmDown(site Site, failed SiteStatus) SiteStatus {
+	confirmation := checkOnce(site)
+	if confirmation.IsUp {
+		logEvent("info", siteFields(site), "🔂 %s : échec passager démenti par la confirmation (%s)", site.Name, failureMessage(failed))
+		confirmation.TransientBlips = 1
+		return confirmation
+	}
+	confirmation.ConfirmedDown = true
+	return confirmation
+}
+
+// isRetryable indique si un échec est transitoire : erreur de connexion ou erreur serveur
+func isRetryable(status SiteStatus) bool {
+	return status.StatusCode == 0 || status.StatusCode >= 500
+}
+
+// Types de vérification ; http par défaut
+const (
+	checkTypeHTTP = "http"
+	checkTypeTCP  = "tcp"
+	checkTypeGRPC = "grpc"
+)
+
+// checkers associe chaque type de vérification à sa fonction
+var checkers = map[string]func(Site) SiteStatus{
+	checkTypeHTTP: checkHTTP,
+	checkTypeTCP:  checkTCP,
+	checkTypeGRPC: checkGRPC,
+}
+
+// checkType renvoie le type de vérification du site (http par défaut)
+func checkType(site Site) string {
+	if site.Type == "" {
+		return checkTypeHTTP
+	}
+	return strings.ToLower(site.Type)
+}
+
+// checkOnce effectue une seule vérification du site selon son type.
+// Un site qui répond au-delà de DegradedMs passe à l'état dégradé tout en restant up.
+func checkOnce(site Site) SiteStatus {
+	check := checkers[checkType(site)]
+	if len(site.Endpoints) > 0 {
+		check = checkEndpoints
+	}
+	status := check(site)
+	// Échecs sans erreur réseau : contenu du corps, version de protocole...
+	if !status.IsUp && status.ErrorType == "" {
+		status.ErrorType = errorTypeOther
+	}
+	if status.State == stateUp && site.DegradedMs > 0 && status.ResponseTime > site.DegradedMs {
+		status.State = stateDegraded
+		status.Error = fmt.Sprintf("temps de réponse %dms au-delà du seuil de %dms", status.ResponseTime, site.DegradedMs)
+	}
+	return status
 }
 
-// checkSite effectue une requête GET vers site.URL et renvoie un SiteStatus
-func checkSite(site Site) SiteStatus {
+// checkHTTP effectue une requête vers site.URL et renvoie un SiteStatus
+func checkHTTP(site Site) SiteStatus {
 	start := time.Now()
 
+	transport := transportFor(site)
 	client := &http.Client{
-		Timeout: 10 * time.Second,
+		Timeout:   siteTimeout(site),
+		Transport: transport,
+	}
+	var redirects int
+	client.CheckRedirect = func(_ *http.Request, via []*http.Request) error {
+		if site.FlagRedirects || !followRedirects(site) {
+			return http.ErrUseLastResponse
+		}
+		// Même limite que la politique par défaut du client
+		if len(via) >= 10 {
+			return errors.New("arrêt après 10 redirections")
+		}
+		redirects = len(via)
+		return nil
+	}
+
+	// Le trace permet de savoir quelle connexion (adresses locale et distante) a servi
+	// et de décomposer la latence
+	var localAddr, remoteAddr string
+	var dnsStart, connectStart, tlsStart time.Time
+	var dnsMs, connectMs, tlsMs, ttfbMs *int64
+	since := func(t time.Time) *int64 {
+		ms := time.Since(t).Milliseconds()
+		return &ms
+	}
+	trace := &httptrace.ClientTrace{
+		DNSStart:             func(httptrace.DNSStartInfo) { dnsStart = time.Now() },
+		DNSDone:              func(httptrace.DNSDoneInfo) { dnsMs = since(dnsStart) },
+		ConnectStart:         func(string, string) { connectStart = time.Now() },
+		ConnectDone:          func(string, string, error) { connectMs = since(connectStart) },
+		TLSHandshakeStart:    func() { tlsStart = time.Now() },
+		TLSHandshakeDone:     func(tls.ConnectionState, error) { tlsMs = since(tlsStart) },
+		GotFirstResponseByte: func() { ttfbMs = since(start) },
+		GotConn: func(info httptrace.GotConnInfo) {
+			localAddr = info.Conn.LocalAddr().String()
+			remoteAddr = info.Conn.RemoteAddr().String()
+		},
 	}
+	ctx := httptrace.WithClientTrace(context.Background(), trace)
 
-	resp, err := client.Get(site.URL)
-	duration := time.Since(start).Milliseconds()
+	var resp *http.Response
+	req, err := newCheckRequest(ctx, site)
+	if err == nil {
+		resp, err = client.Do(req)
+	}
+	received := time.Now()
+	elapsed := received.Sub(start)
 
 	status := SiteStatus{
-		Site:         site,
-		ResponseTime: duration,
-		LastChecked:  time.Now(),
+		Site:             site,
+		ResponseTime:     elapsed.Milliseconds(),
+		ResponseTimeUs:   elapsed.Microseconds(),
+		RequestStartedAt: start,
 	}
 
 	if err != nil {
 		status.IsUp = false
 		status.Error = err.Error()
+		status.ErrorType = classifyError(err)
+		if msg, ok := proxyError(transport, req, err); ok {
+			status.Error = msg
+		} else if family := dialFamily(err); family != "" {
+			status.Error += " (" + family + ")"
+		}
 		status.StatusCode = 0
 	} else {
+		status.ResponseReceivedAt = &received
 		status.StatusCode = resp.StatusCode
-		status.IsUp = resp.StatusCode >= 200 && resp.StatusCode < 400
+		status.Protocol = resp.Proto
+		status.ResponseHeaders = captureHeaders(site, resp.Header)
+		if redirects > 0 {
+			status.Redirects = redirects
+			status.FinalURL = resp.Request.URL.String()
+		}
+		if resp.StatusCode >= 300 && resp.StatusCode < 400 && !followRedirects(site) {
+			status.Location = resp.Header.Get("Location")
+		}
+		recordCertExpiry(&status, resp.TLS)
+		status.DNSMs, status.ConnectMs, status.TLSMs, status.TTFBMs = dnsMs, connectMs, tlsMs, ttfbMs
+		if site.SourceIP != "" {
+			status.SourceAddr = localAddr
+		}
+		// Connexion de la dernière réponse : après des redirections, celle de l'URL finale
+		status.RemoteAddr = remoteAddr
+		if site.WatchContent {
+			status.ETag = resp.Header.Get("ETag")
+			status.LastModified = resp.Header.Get("Last-Modified")
+		}
+		status.IsUp, status.Error = statusCodeAccepted(site, resp.StatusCode)
+		if sendsBody(site) {
+			accepted := status.IsUp
+			status.PayloadAccepted = &accepted
+		}
+		if !status.IsUp {
+			status.ErrorType = errorTypeHTTPStatus
+		}
+		if !hasExpectedStatus(site) && site.FlagRedirects && resp.StatusCode >= 300 && resp.StatusCode < 400 {
+			status.IsUp = false
+			status.ErrorType = errorTypeHTTPStatus
+			status.State = stateRedirected
+			status.Location = resp.Header.Get("Location")
+			status.Error = fmt.Sprintf("redirection %d vers %s", resp.StatusCode, status.Location)
+		}
+		if status.IsUp && site.MinHTTPVersion != "" {
+			checkHTTPVersion(&status, site.MinHTTPVersion, resp)
+		}
+
+		// Tout ce qui lit le corps passe par body pour que les octets soient comptés ;
+		// une vérification limitée au code de statut ne lit rien.
+		body := &countingReader{r: resp.Body}
+		if needsBody(site) {
+			inspectBody(&status, site, body, resp.ContentLength)
+		}
+		status.BytesRead = body.n
 		resp.Body.Close()
 	}
+	if status.State == "" {
+		status.State = stateDown
+		if status.IsUp {
+			status.State = stateUp
+		}
+	}
+	status.LastChecked = time.Now()
 	return status
 }
 
+// defaultTimeout est le délai d'une vérification sans timeout_seconds
+const defaultTimeout = 10 * time.Second
+
+// siteTimeout renvoie le délai maximal applicable à une vérification du site
+func siteTimeout(site Site) time.Duration {
+	if site.TimeoutSeconds > 0 {
+		return time.Duration(site.TimeoutSeconds) * time.Second
+	}
+	return defaultTimeout
+}
+
+// parseHTTPVersion lit une version "2", "1.1" ou "HTTP/1.1"
+func parseHTTPVersion(v string) (major, minor int, err error) {
+	v = strings.TrimPrefix(strings.ToUpper(v), "HTTP/")
+	majStr, minStr, _ := strings.Cut(v, ".")
+	if major, err = strconv.Atoi(majStr); err != nil {
+		return 0, 0, fmt.Errorf("min_http_version %q invalide", v)
+	}
+	if minStr != "" {
+		if minor, err = strconv.Atoi(minStr); err != nil {
+			return 0, 0, fmt.Errorf("min_http_version %q invalide", v)
+		}
+	}
+	return major, minor, nil
+}
+
+// checkHTTPVersion passe le site en panne si le protocole négocié est inférieur au minimum
+func checkHTTPVersion(status *SiteStatus, minVersion string, resp *http.Response) {
+	major, minor, _ := parseHTTPVersion(minVersion) // validé au chargement
+	if resp.ProtoMajor > major || (resp.ProtoMajor == major && resp.ProtoMinor >= minor) {
+		return
+	}
+	status.IsUp = false
+	status.Error = fmt.Sprintf("protocole %s inférieur au minimum attendu HTTP/%d.%d", resp.Proto, major, minor)
+}
+
+// followRedirects indique si les redirections du site sont suivies (FollowRedirects absent : oui)
+func followRedirects(site Site) bool {
+	return site.FollowRedirects == nil || *site.FollowRedirects
+}
+
+// hasExpectedStatus indique si le site fixe lui-même les codes HTTP acceptables
+func hasExpectedStatus(site Site) bool {
+	return len(site.AcceptableStatus) > 0 || site.ExpectedStatus != 0
+}
+
+// treat3xxAsUp étend la règle par défaut aux codes 3xx (TREAT_3XX_AS_UP). Le défaut reste true
+// par compatibilité : un 3xx était toujours up avant ce réglage, et le passer à false changerait
+// l'état de sites existants sans modification de leur configuration. À false, seul un 2xx est up
+// pour les sites sans ExpectedStatus ni AcceptableStatus (qui priment toujours sur ce réglage).
+// Une redirection n'est vue que si elle n'est pas suivie (follow_redirects: false).
+var treat3xxAsUp = true
+
+// loadStatusPolicyConfig lit TREAT_3XX_AS_UP et annonce la règle appliquée
+func loadStatusPolicyConfig() {
+	treat3xxAsUp = envBool("TREAT_3XX_AS_UP", true)
+	if treat3xxAsUp {
+		logInfo("🚦 Codes considérés up par défaut : 200–399 (TREAT_3XX_AS_UP=false pour exiger un 2xx)")
+	} else {
+		logInfo("🚦 Codes considérés up par défaut : 200–299 (TREAT_3XX_AS_UP=false)")
+	}
+}
+
+// statusCodeAccepted applique AcceptableStatus, sinon ExpectedStatus, sinon la règle par défaut
+// (200–399, ou 200–299 sans treat3xxAsUp), et décrit le refus éventuel
+func statusCodeAccepted(site Site, code int) (bool, string) {
+	switch {
+	case len(site.AcceptableStatus) > 0:
+		if slices.Contains(site.AcceptableStatus, code) {
+			return true, ""
+		}
+		allowed := make([]string, len(site.AcceptableStatus))
+		for i, c := range site.AcceptableStatus {
+			allowed[i] = strconv.Itoa(c)
+		}
+		return false, fmt.Sprintf("code HTTP %d, attendu parmi %s", code, strings.Join(allowed, ", "))
+	case site.ExpectedStatus != 0:
+		if code == site.ExpectedStatus {
+			return true, ""
+		}
+		return false, fmt.Sprintf("code HTTP %d, attendu %d", code, site.ExpectedStatus)
+	case !treat3xxAsUp && code >= 300 && code < 400:
+		return false, fmt.Sprintf("code HTTP %d : redirection non acceptée (TREAT_3XX_AS_UP=false)", code)
+	default:
+		return code >= 200 && code < 400, ""
+	}
+}
+
+// captureHeaders recopie les en-têtes demandés par le site ; nil si aucun n'est présent.
+// Un en-tête répété est joint par des virgules, comme le permet RFC 9110.
+func captureHeaders(site Site, h http.Header) map[string]string {
+	var captured map[string]string
+	for _, name := range site.CaptureHeaders {
+		values := h.Values(name)
+		if len(values) == 0 {
+			continue
+		}
+		if captured == nil {
+			captured = make(map[string]string, len(site.CaptureHeaders))
+		}
+		captured[http.CanonicalHeaderKey(name)] = strings.Join(values, ", ")
+	}
+	return captured
+}
+
+// countingReader compte les octets lus à travers lui
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
 // --- Handlers HTTP ---
 
 // handleSites renvoie la liste des sites (sans métadonnées)
 func handleSites(w http.ResponseWriter, r *http.Request) {
+	list := currentSites()
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(sites)
+	writeJSON(w, r, list)
+}
+
+// handleSiteByID renvoie la définition d'un seul site, ou 404 si l'ID est inconnu
+func handleSiteByID(w http.ResponseWriter, r *http.Request) {
+	statusMutex.RLock()
+	site, ok := findSite(r.PathValue("id"))
+	statusMutex.RUnlock()
+
+	if !ok {
+		http.Error(w, "Site introuvable", http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	writeJSON(w, r, site)
+}
+
+// findSite cherche un site par son ID (l'appelant doit détenir statusMutex)
+func findSite(id string) (Site, bool) {
+	for _, s := range sites {
+		if s.ID == id {
+			return s, true
+		}
+	}
+	return Site{}, false
 }
 
-// handleStatus renvoie le statut actuel de tous les sites
+// handleStatus renvoie le statut actuel de tous les sites.
+// Le paramètre ?state=up|degraded|down|pending|redirected restreint la liste aux sites dans cet état,
+// ?group= à ceux d'un groupe (ungrouped pour les sites sans groupe) ;
+// ?limit= et ?offset= paginent le résultat (voir parsePagination).
 func handleStatus(w http.ResponseWriter, r *http.Request) {
+	state := r.URL.Query().Get("state")
+	switch state {
+	case "", stateUp, stateDegraded, stateDown, statePending, stateRedirected, statePaused:
+	default:
+		http.Error(w, fmt.Sprintf("state %q inconnu (valeurs possibles : up, degraded, down, pending, redirected, paused)", state), http.StatusBadRequest)
+		return
+	}
+	group := r.URL.Query().Get("group")
+	limit, offset, paged, err := parsePagination(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	statusMutex.RLock()
 	defer statusMutex.RUnlock()
 
+	result := statuses
+	if state != "" || group != "" {
+		result = make([]SiteStatus, 0, len(statuses))
+		for _, st := range statuses {
+			if (state == "" || st.State == state) && (group == "" || siteGroup(st.Site) == group) {
+				result = append(result, st)
+			}
+		}
+	}
+
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(statuses)
+	setStatusCacheHeaders(w)
+	if paged {
+		writeJSON(w, r, paginate(result, limit, offset))
+		return
+	}
+	writeJSON(w, r, result)
+}
+
+// Pagination de /api/status
+const (
+	defaultPageLimit = 50
+	maxPageLimit     = 500
+)
+
+// StatusPage est l'enveloppe renvoyée par /api/status quand limit ou offset est fourni
+type StatusPage struct {
+	Data   []SiteStatus `json:"data"`
+	Total  int          `json:"total"`
+	Limit  int          `json:"limit"`
+	Offset int          `json:"offset"`
+}
+
+// parsePagination lit ?limit= et ?offset=. Sans l'un ni l'autre, paged est faux et
+// la liste complète est renvoyée comme avant, sans enveloppe.
+func parsePagination(r *http.Request) (limit, offset int, paged bool, err error) {
+	q := r.URL.Query()
+	if !q.Has("limit") && !q.Has("offset") {
+		return 0, 0, false, nil
+	}
+	limit = defaultPageLimit
+	if v := q.Get("limit"); v != "" {
+		if limit, err = strconv.Atoi(v); err != nil || limit <= 0 {
+			return 0, 0, true, fmt.Errorf("limit %q invalide (entier positif attendu)", v)
+		}
+		limit = min(limit, maxPageLimit)
+	}
+	if v := q.Get("offset"); v != "" {
+		if offset, err = strconv.Atoi(v); err != nil || offset < 0 {
+			return 0, 0, true, fmt.Errorf("offset %q invalide (entier positif ou nul attendu)", v)
+		}
+	}
+	return limit, offset, true, nil
+}
+
+// paginate découpe la liste ; un offset au-delà de la fin donne une page vide
+func paginate(list []SiteStatus, limit, offset int) StatusPage {
+	page := StatusPage{Data: []SiteStatus{}, Total: len(list), Limit: limit, Offset: offset}
+	if offset < len(list) {
+		page.Data = list[offset:min(offset+limit, len(list))]
+	}
+	return page
 }
 
-// handleHealth renvoie un JSON simple pour le healthcheck
+// handleHealth renvoie un JSON simple pour le healthcheck ; 503 si la boucle de monitoring
+// semble bloquée (voir monitoringStale) ou si l'auto-test obligatoire a échoué
 func handleHealth(w http.ResponseWriter, r *http.Request) {
 	uptime := time.Since(startTime).String()
+
+	statusMutex.RLock()
+	isolated := monitorIsolated
+	monitored := len(sites)
+	active := len(activeSites(sites))
+	statusMutex.RUnlock()
+
+	state := "ok"
+	code := http.StatusOK
+	var warning string
+	if monitored == 0 {
+		// Souvent un mauvais chemin de configuration : le service tourne mais ne surveille rien
+		state = "warning"
+		warning = fmt.Sprintf("aucun site configuré (%s)", sitesConfigPath)
+	} else if active == 0 {
+		// Aucune passe n'a lieu tant que tous les sites sont en pause, sans que ce soit un blocage
+		state = "warning"
+		warning = "tous les sites sont en pause"
+	}
+	if isolated {
+		state = "degraded"
+	}
+	// Boucle de monitoring bloquée : l'API répond mais les statuts ne sont plus à jour
+	stale := monitoringStale()
+	if stale {
+		state = "degraded"
+		code = http.StatusServiceUnavailable
+	}
+	if canaryBlocksReadiness() {
+		state = "canary_failed"
+		code = http.StatusServiceUnavailable
+	}
 	health := map[string]interface{}{
-		"status":    "ok",
+		"status":    state,
 		"timestamp": time.Now().UTC(),
 		"uptime":    uptime,
+		"isolated":  isolated,
+
+		"interval_seconds": int(currentInterval().Seconds()),
+		"canary_failed":    canaryFailed,
+
+		"sites_monitored":        monitored,
+		"last_check_age_seconds": int(lastPassAge().Seconds()),
+		"monitoring_stale":       stale,
+	}
+	if warning != "" {
+		health["warning"] = warning
 	}
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(health)
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(code)
+	writeJSON(w, r, health)
+}
+
+// minIntervalSeconds est le plancher accepté pour l'intervalle de vérification
+const minIntervalSeconds = 5
+
+// handleSetInterval modifie l'intervalle global de vérification : {"interval_seconds": 15}
+func handleSetInterval(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		IntervalSeconds int `json:"interval_seconds"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "JSON invalide", http.StatusBadRequest)
+		return
+	}
+	if body.IntervalSeconds < minIntervalSeconds {
+		http.Error(w, fmt.Sprintf("interval_seconds doit être au moins %d", minIntervalSeconds), http.StatusBadRequest)
+		return
+	}
+
+	setInterval(time.Duration(body.IntervalSeconds) * time.Second)
+
+	w.Header().Set("Content-Type", "application/json")
+	writeJSON(w, r, map[string]int{"interval_seconds": body.IntervalSeconds})
+}
+
+// pongBody est alloué une seule fois : /api/ping est sondé à haute fréquence
+var pongBody = []byte("pong")
+
+// handlePing répond "pong" en texte brut, sans verrou ni JSON, pour les sondes de load balancer
+func handlePing(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Write(pongBody)
+}
+
+// loadCacheConfig lit STATUS_CACHE_SECONDS et STATUS_CACHE_CONTROL.
+// Par défaut les réponses de statut ne sont pas mises en cache (no-store).
+func loadCacheConfig() {
+	statusCacheSeconds = envInt("STATUS_CACHE_SECONDS", 0)
+	def := "no-store"
+	if statusCacheSeconds > 0 {
+		def = fmt.Sprintf("public, max-age=%d", statusCacheSeconds)
+	}
+	statusCacheControl = envString("STATUS_CACHE_CONTROL", def)
+	logInfo("🗄️ Cache des endpoints de statut : %s", statusCacheControl)
+}
+
+// setStatusCacheHeaders ajoute Cache-Control (et Expires si une durée est configurée)
+func setStatusCacheHeaders(w http.ResponseWriter) {
+	w.Header().Set("Cache-Control", statusCacheControl)
+	if statusCacheSeconds > 0 {
+		expires := time.Now().Add(time.Duration(statusCacheSeconds) * time.Second)
+		w.Header().Set("Expires", expires.UTC().Format(http.TimeFormat))
+	}
+}
+
+// writeJSON encode v dans la réponse : compact par défaut, indenté avec ?pretty=1
+// ou l'en-tête X-Pretty: 1 pour une lecture à la main. Les en-têtes (Content-Type, cache,
+// code de statut) restent à la charge de l'appelant.
+func writeJSON(w http.ResponseWriter, r *http.Request, v any) {
+	enc := json.NewEncoder(w)
+	if wantsPretty(r) {
+		enc.SetIndent("", "  ")
+	}
+	enc.Encode(v)
+}
+
+// wantsPretty indique si le client demande du JSON indenté
+func wantsPretty(r *http.Request) bool {
+	v := r.URL.Query().Get("pretty")
+	if v == "" {
+		v = r.Header.Get("X-Pretty")
+	}
+	pretty, _ := strconv.ParseBool(v)
+	return pretty
 }
 
 // recoveryMiddleware intercepte une panic dans un handler et renvoie un 500
@@ -247,7 +1510,7 @@ func recoveryMiddleware(next http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		defer func() {
 			if rec := recover(); rec != nil {
-				log.Printf("⚠️ Panic interceptée dans handler: %v", rec)
+				logWarn("⚠️ Panic interceptée dans handler: %v", rec)
 				http.Error(w, "Erreur interne du serveur", http.StatusInternalServerError)
 			}
 		}()
@@ -255,14 +1518,53 @@ func recoveryMiddleware(next http.HandlerFunc) http.HandlerFunc {
 	}
 }
 
-// corsMiddleware enveloppe un http.Handler et ajoute les en-têtes CORS
+// corsAllowedOrigins liste blanche CORS_ALLOWED_ORIGINS (séparée par des virgules) ;
+// nil : toutes les origines sont acceptées (Access-Control-Allow-Origin: *)
+var corsAllowedOrigins map[string]bool
+
+// loadCORSConfig lit CORS_ALLOWED_ORIGINS
+func loadCORSConfig() {
+	v := os.Getenv("CORS_ALLOWED_ORIGINS")
+	if v == "" {
+		return
+	}
+	corsAllowedOrigins = make(map[string]bool)
+	for _, origin := range strings.Split(v, ",") {
+		if origin = strings.TrimSuffix(strings.TrimSpace(origin), "/"); origin != "" {
+			corsAllowedOrigins[origin] = true
+		}
+	}
+	logInfo("🌍 CORS limité à %d origine(s), avec identifiants", len(corsAllowedOrigins))
+}
+
+// corsMiddleware enveloppe un http.Handler et ajoute les en-têtes CORS.
+// Avec une liste blanche, seule une origine connue est renvoyée (avec Allow-Credentials) ;
+// une requête préliminaire d'une autre origine est refusée.
 func corsMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+		allowed := true
+		if corsAllowedOrigins == nil {
+			w.Header().Set("Access-Control-Allow-Origin", "*")
+		} else {
+			// La réponse dépend de l'origine : les caches ne doivent pas la partager
+			w.Header().Add("Vary", "Origin")
+			origin := r.Header.Get("Origin")
+			allowed = origin == "" || corsAllowedOrigins[origin]
+			if origin != "" && allowed {
+				w.Header().Set("Access-Control-Allow-Origin", origin)
+				w.Header().Set("Access-Control-Allow-Credentials", "true")
+			}
+		}
+		if allowed {
+			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
+			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-API-Key, X-Pretty")
+		}
 
 		if r.Method == http.MethodOptions {
+			if !allowed {
+				http.Error(w, "Origine non autorisée", http.StatusForbidden)
+				return
+			}
 			w.WriteHeader(http.StatusOK)
 			return
 		}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"fmt"
+	"time"
+	_ "time/tzdata" // fuseaux horaires disponibles même sans base zoneinfo sur l'hôte
+)
+
+// MaintenanceWindow est une plage pendant laquelle le site est vérifié sans alerte.
+// Ponctuelle : Start et End au format RFC 3339 (ex. "2026-10-14T22:00:00+02:00").
+// Quotidienne (Daily) : Start et End au format "15:04" dans Timezone (heure locale du
+// serveur par défaut) ; une fin antérieure au début franchit minuit.
+type MaintenanceWindow struct {
+	Start    string `json:"start"`
+	End      string `json:"end"`
+	Daily    bool   `json:"daily,omitempty"`
+	Timezone string `json:"timezone,omitempty"`
+}
+
+// active indique si la plage couvre l'instant now
+func (mw MaintenanceWindow) active(now time.Time) (bool, error) {
+	if !mw.Daily {
+		start, err := time.Parse(time.RFC3339, mw.Start)
+		if err != nil {
+			return false, fmt.Errorf("début %q invalide (RFC 3339 attendu)", mw.Start)
+		}
+		end, err := time.Parse(time.RFC3339, mw.End)
+		if err != nil {
+			return false, fmt.Errorf("fin %q invalide (RFC 3339 attendu)", mw.End)
+		}
+		if !end.After(start) {
+			return false, fmt.Errorf("la fin %s précède le début %s", mw.End, mw.Start)
+		}
+		return !now.Before(start) && now.Before(end), nil
+	}
+
+	loc := time.Local
+	if mw.Timezone != "" {
+		var err error
+		if loc, err = time.LoadLocation(mw.Timezone); err != nil {
+			return false, fmt.Errorf("fuseau %q inconnu", mw.Timezone)
+		}
+	}
+	start, err := time.Parse("15:04", mw.Start)
+	if err != nil {
+		return false, fmt.Errorf("début %q invalide (HH:MM attendu)", mw.Start)
+	}
+	end, err := time.Parse("15:04", mw.End)
+	if err != nil {
+		return false, fmt.Errorf("fin %q invalide (HH:MM attendu)", mw.End)
+	}
+
+	local := now.In(loc)
+	minute := local.Hour()*60 + local.Minute()
+	from := start.Hour()*60 + start.Minute()
+	to := end.Hour()*60 + end.Minute()
+	if from <= to {
+		return minute >= from && minute < to, nil
+	}
+	return minute >= from || minute < to, nil
+}
+
+// validateMaintenance vérifie les plages de maintenance d'un site
+func validateMaintenance(s Site) error {
+	for i, mw := range s.Maintenance {
+		if _, err := mw.active(time.Now()); err != nil {
+			return fmt.Errorf("maintenance #%d : %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+// inMaintenance indique si au moins une plage du site couvre now (les plages peuvent se chevaucher)
+func inMaintenance(s Site, now time.Time) bool {
+	for _, mw := range s.Maintenance {
+		if ok, _ := mw.active(now); ok {
+			return true
+		}
+	}
+	return false
+}
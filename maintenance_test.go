@@ -0,0 +1,71 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMaintenanceWindowActive(t *testing.T) {
+	paris, err := time.LoadLocation("Europe/Paris")
+	if err != nil {
+		t.Fatal(err)
+	}
+	at := func(hour, minute int) time.Time { return time.Date(2026, 10, 14, hour, minute, 0, 0, paris) }
+
+	tests := []struct {
+		name    string
+		mw      MaintenanceWindow
+		now     time.Time
+		want    bool
+		wantErr bool
+	}{
+		{"ponctuelle, pendant", MaintenanceWindow{Start: "2026-10-14T22:00:00+02:00", End: "2026-10-14T23:00:00+02:00"}, at(22, 30), true, false},
+		{"ponctuelle, début inclus", MaintenanceWindow{Start: "2026-10-14T22:00:00+02:00", End: "2026-10-14T23:00:00+02:00"}, at(22, 0), true, false},
+		{"ponctuelle, fin exclue", MaintenanceWindow{Start: "2026-10-14T22:00:00+02:00", End: "2026-10-14T23:00:00+02:00"}, at(23, 0), false, false},
+		{"ponctuelle, autre fuseau", MaintenanceWindow{Start: "2026-10-14T20:00:00Z", End: "2026-10-14T21:00:00Z"}, at(22, 30), true, false},
+		{"ponctuelle, fin avant début", MaintenanceWindow{Start: "2026-10-14T23:00:00+02:00", End: "2026-10-14T22:00:00+02:00"}, at(22, 30), false, true},
+		{"ponctuelle, date invalide", MaintenanceWindow{Start: "demain", End: "2026-10-14T23:00:00+02:00"}, at(22, 30), false, true},
+		{"quotidienne, pendant", MaintenanceWindow{Start: "02:00", End: "04:00", Daily: true, Timezone: "Europe/Paris"}, at(3, 0), true, false},
+		{"quotidienne, après", MaintenanceWindow{Start: "02:00", End: "04:00", Daily: true, Timezone: "Europe/Paris"}, at(4, 0), false, false},
+		{"quotidienne franchissant minuit, avant minuit", MaintenanceWindow{Start: "23:00", End: "01:00", Daily: true, Timezone: "Europe/Paris"}, at(23, 30), true, false},
+		{"quotidienne franchissant minuit, après minuit", MaintenanceWindow{Start: "23:00", End: "01:00", Daily: true, Timezone: "Europe/Paris"}, at(0, 30), true, false},
+		{"quotidienne franchissant minuit, hors plage", MaintenanceWindow{Start: "23:00", End: "01:00", Daily: true, Timezone: "Europe/Paris"}, at(12, 0), false, false},
+		{"quotidienne, fuseau appliqué", MaintenanceWindow{Start: "02:00", End: "04:00", Daily: true, Timezone: "UTC"}, at(3, 0), false, false},
+		{"quotidienne, fuseau inconnu", MaintenanceWindow{Start: "02:00", End: "04:00", Daily: true, Timezone: "Mars/Olympus"}, at(3, 0), false, true},
+		{"quotidienne, heure invalide", MaintenanceWindow{Start: "2h", End: "04:00", Daily: true}, at(3, 0), false, true},
+	}
+	for _, tt := range tests {
+		got, err := tt.mw.active(tt.now)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s : erreur %v, attendue : %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s : active = %v, attendu %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestInMaintenance(t *testing.T) {
+	now := time.Date(2026, 10, 14, 3, 0, 0, 0, time.UTC)
+	night := MaintenanceWindow{Start: "02:00", End: "04:00", Daily: true, Timezone: "UTC"}
+	noon := MaintenanceWindow{Start: "12:00", End: "13:00", Daily: true, Timezone: "UTC"}
+	broken := MaintenanceWindow{Start: "x", End: "y", Daily: true}
+
+	tests := []struct {
+		name    string
+		windows []MaintenanceWindow
+		want    bool
+	}{
+		{"aucune plage", nil, false},
+		{"plage active", []MaintenanceWindow{night}, true},
+		{"plage inactive", []MaintenanceWindow{noon}, false},
+		{"une plage active parmi plusieurs", []MaintenanceWindow{noon, night}, true},
+		{"plage invalide ignorée", []MaintenanceWindow{broken}, false},
+	}
+	for _, tt := range tests {
+		if got := inMaintenance(Site{Maintenance: tt.windows}, now); got != tt.want {
+			t.Errorf("%s : inMaintenance = %v, attendu %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Métriques propres au moniteur, exposées au format texte Prometheus sur /metrics
+var (
+	passMetricsMutex  sync.Mutex
+	passTotal         uint64
+	lastPassDuration  time.Duration
+	lastPassTimestamp time.Time
+)
+
+// Compteurs de vérifications, globaux et par site, exposés en JSON sur /api/metrics
+var (
+	checkCountersMutex sync.Mutex
+	checksTotal        uint64
+	failuresTotal      uint64
+	siteCounters       = make(map[string]*CheckCounters)
+)
+
+// CheckCounters compte les vérifications d'un site depuis le démarrage
+type CheckCounters struct {
+	Checks   uint64 `json:"checks"`
+	Failures uint64 `json:"failures"`
+}
+
+// recordCheck compte une vérification terminée ; ses éventuelles nouvelles tentatives ne comptent pas à part
+func recordCheck(st SiteStatus) {
+	checkCountersMutex.Lock()
+	defer checkCountersMutex.Unlock()
+	c, ok := siteCounters[st.Site.ID]
+	if !ok {
+		c = &CheckCounters{}
+		siteCounters[st.Site.ID] = c
+	}
+	checksTotal++
+	c.Checks++
+	if !st.IsUp {
+		failuresTotal++
+		c.Failures++
+	}
+}
+
+// recordPass enregistre la fin d'une passe de vérification
+func recordPass(start time.Time) {
+	passMetricsMutex.Lock()
+	defer passMetricsMutex.Unlock()
+	passTotal++
+	lastPassDuration = time.Since(start)
+	lastPassTimestamp = time.Now()
+}
+
+// handleMetrics écrit les métriques du moniteur au format d'exposition Prometheus
+func handleMetrics(w http.ResponseWriter, r *http.Request) {
+	statusMutex.RLock()
+	sitesTotal := len(sites)
+	statusMutex.RUnlock()
+
+	passMetricsMutex.Lock()
+	total, duration, last := passTotal, lastPassDuration, lastPassTimestamp
+	passMetricsMutex.Unlock()
+
+	var lastSeconds float64
+	if !last.IsZero() {
+		lastSeconds = float64(last.UnixNano()) / 1e9
+	}
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	writeMetric(w, "monitor_pass_duration_seconds", "gauge", "Durée de la dernière passe de vérification.", duration.Seconds())
+	writeMetric(w, "monitor_pass_total", "counter", "Nombre de passes de vérification terminées.", float64(total))
+	writeMetric(w, "monitor_sites_total", "gauge", "Nombre de sites surveillés.", float64(sitesTotal))
+	writeMetric(w, "monitor_last_pass_timestamp_seconds", "gauge", "Horodatage Unix de la fin de la dernière passe.", lastSeconds)
+}
+
+// writeMetric écrit une métrique sans label avec ses lignes HELP et TYPE
+func writeMetric(w http.ResponseWriter, name, kind, help string, value float64) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n%s %g\n", name, help, name, kind, name, value)
+}
+
+// MonitorMetrics est la réponse de /api/metrics
+type MonitorMetrics struct {
+	UptimeSeconds int64                    `json:"uptime_seconds"`
+	PassesTotal   uint64                   `json:"passes_total"`
+	ChecksTotal   uint64                   `json:"checks_total"`
+	FailuresTotal uint64                   `json:"failures_total"`
+	Sites         map[string]CheckCounters `json:"sites"`
+}
+
+// handleMetricsJSON renvoie les compteurs de vérifications, pour contrôler que
+// le planificateur tourne à la cadence attendue
+func handleMetricsJSON(w http.ResponseWriter, r *http.Request) {
+	passMetricsMutex.Lock()
+	m := MonitorMetrics{
+		UptimeSeconds: int64(time.Since(startTime).Seconds()),
+		PassesTotal:   passTotal,
+	}
+	passMetricsMutex.Unlock()
+
+	checkCountersMutex.Lock()
+	m.ChecksTotal, m.FailuresTotal = checksTotal, failuresTotal
+	m.Sites = make(map[string]CheckCounters, len(siteCounters))
+	for id, c := range siteCounters {
+		m.Sites[id] = *c
+	}
+	checkCountersMutex.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(w, r, m)
+}
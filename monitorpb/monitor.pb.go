@@ -0,0 +1,488 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.12
+// 	protoc        (unknown)
+// source: monitorpb/monitor.proto
+
+// Définitions gRPC du moniteur, calquées sur les structures Site et SiteStatus.
+
+package monitorpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Site représente un site à surveiller
+type Site struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Url           string                 `protobuf:"bytes,3,opt,name=url,proto3" json:"url,omitempty"`
+	Description   string                 `protobuf:"bytes,4,opt,name=description,proto3" json:"description,omitempty"`
+	Runbook       string                 `protobuf:"bytes,5,opt,name=runbook,proto3" json:"runbook,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Site) Reset() {
+	*x = Site{}
+	mi := &file_monitorpb_monitor_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Site) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Site) ProtoMessage() {}
+
+func (x *Site) ProtoReflect() protoreflect.Message {
+	mi := &file_monitorpb_monitor_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Site.ProtoReflect.Descriptor instead.
+func (*Site) Descriptor() ([]byte, []int) {
+	return file_monitorpb_monitor_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Site) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Site) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Site) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *Site) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *Site) GetRunbook() string {
+	if x != nil {
+		return x.Runbook
+	}
+	return ""
+}
+
+// SiteStatus contient le statut d'un site après vérification
+type SiteStatus struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Site           *Site                  `protobuf:"bytes,1,opt,name=site,proto3" json:"site,omitempty"`
+	IsUp           bool                   `protobuf:"varint,2,opt,name=is_up,json=isUp,proto3" json:"is_up,omitempty"`
+	State          string                 `protobuf:"bytes,3,opt,name=state,proto3" json:"state,omitempty"`
+	ResponseTimeMs int64                  `protobuf:"varint,4,opt,name=response_time_ms,json=responseTimeMs,proto3" json:"response_time_ms,omitempty"`
+	ResponseTimeUs int64                  `protobuf:"varint,5,opt,name=response_time_us,json=responseTimeUs,proto3" json:"response_time_us,omitempty"`
+	StatusCode     int32                  `protobuf:"varint,6,opt,name=status_code,json=statusCode,proto3" json:"status_code,omitempty"`
+	LastChecked    *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=last_checked,json=lastChecked,proto3" json:"last_checked,omitempty"`
+	Error          string                 `protobuf:"bytes,8,opt,name=error,proto3" json:"error,omitempty"`
+	Protocol       string                 `protobuf:"bytes,9,opt,name=protocol,proto3" json:"protocol,omitempty"`
+	Location       string                 `protobuf:"bytes,10,opt,name=location,proto3" json:"location,omitempty"`
+	BytesRead      int64                  `protobuf:"varint,11,opt,name=bytes_read,json=bytesRead,proto3" json:"bytes_read,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *SiteStatus) Reset() {
+	*x = SiteStatus{}
+	mi := &file_monitorpb_monitor_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SiteStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SiteStatus) ProtoMessage() {}
+
+func (x *SiteStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_monitorpb_monitor_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SiteStatus.ProtoReflect.Descriptor instead.
+func (*SiteStatus) Descriptor() ([]byte, []int) {
+	return file_monitorpb_monitor_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *SiteStatus) GetSite() *Site {
+	if x != nil {
+		return x.Site
+	}
+	return nil
+}
+
+func (x *SiteStatus) GetIsUp() bool {
+	if x != nil {
+		return x.IsUp
+	}
+	return false
+}
+
+func (x *SiteStatus) GetState() string {
+	if x != nil {
+		return x.State
+	}
+	return ""
+}
+
+func (x *SiteStatus) GetResponseTimeMs() int64 {
+	if x != nil {
+		return x.ResponseTimeMs
+	}
+	return 0
+}
+
+func (x *SiteStatus) GetResponseTimeUs() int64 {
+	if x != nil {
+		return x.ResponseTimeUs
+	}
+	return 0
+}
+
+func (x *SiteStatus) GetStatusCode() int32 {
+	if x != nil {
+		return x.StatusCode
+	}
+	return 0
+}
+
+func (x *SiteStatus) GetLastChecked() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LastChecked
+	}
+	return nil
+}
+
+func (x *SiteStatus) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *SiteStatus) GetProtocol() string {
+	if x != nil {
+		return x.Protocol
+	}
+	return ""
+}
+
+func (x *SiteStatus) GetLocation() string {
+	if x != nil {
+		return x.Location
+	}
+	return ""
+}
+
+func (x *SiteStatus) GetBytesRead() int64 {
+	if x != nil {
+		return x.BytesRead
+	}
+	return 0
+}
+
+type GetStatusRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetStatusRequest) Reset() {
+	*x = GetStatusRequest{}
+	mi := &file_monitorpb_monitor_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetStatusRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetStatusRequest) ProtoMessage() {}
+
+func (x *GetStatusRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_monitorpb_monitor_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetStatusRequest.ProtoReflect.Descriptor instead.
+func (*GetStatusRequest) Descriptor() ([]byte, []int) {
+	return file_monitorpb_monitor_proto_rawDescGZIP(), []int{2}
+}
+
+type GetStatusResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Statuses      []*SiteStatus          `protobuf:"bytes,1,rep,name=statuses,proto3" json:"statuses,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetStatusResponse) Reset() {
+	*x = GetStatusResponse{}
+	mi := &file_monitorpb_monitor_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetStatusResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetStatusResponse) ProtoMessage() {}
+
+func (x *GetStatusResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_monitorpb_monitor_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetStatusResponse.ProtoReflect.Descriptor instead.
+func (*GetStatusResponse) Descriptor() ([]byte, []int) {
+	return file_monitorpb_monitor_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *GetStatusResponse) GetStatuses() []*SiteStatus {
+	if x != nil {
+		return x.Statuses
+	}
+	return nil
+}
+
+type GetStatusByIDRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetStatusByIDRequest) Reset() {
+	*x = GetStatusByIDRequest{}
+	mi := &file_monitorpb_monitor_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetStatusByIDRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetStatusByIDRequest) ProtoMessage() {}
+
+func (x *GetStatusByIDRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_monitorpb_monitor_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetStatusByIDRequest.ProtoReflect.Descriptor instead.
+func (*GetStatusByIDRequest) Descriptor() ([]byte, []int) {
+	return file_monitorpb_monitor_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *GetStatusByIDRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type WatchStatusRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchStatusRequest) Reset() {
+	*x = WatchStatusRequest{}
+	mi := &file_monitorpb_monitor_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchStatusRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchStatusRequest) ProtoMessage() {}
+
+func (x *WatchStatusRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_monitorpb_monitor_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchStatusRequest.ProtoReflect.Descriptor instead.
+func (*WatchStatusRequest) Descriptor() ([]byte, []int) {
+	return file_monitorpb_monitor_proto_rawDescGZIP(), []int{5}
+}
+
+var File_monitorpb_monitor_proto protoreflect.FileDescriptor
+
+const file_monitorpb_monitor_proto_rawDesc = "" +
+	"\n" +
+	"\x17monitorpb/monitor.proto\x12\x0esitemonitor.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"x\n" +
+	"\x04Site\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x10\n" +
+	"\x03url\x18\x03 \x01(\tR\x03url\x12 \n" +
+	"\vdescription\x18\x04 \x01(\tR\vdescription\x12\x18\n" +
+	"\arunbook\x18\x05 \x01(\tR\arunbook\"\x82\x03\n" +
+	"\n" +
+	"SiteStatus\x12(\n" +
+	"\x04site\x18\x01 \x01(\v2\x14.sitemonitor.v1.SiteR\x04site\x12\x13\n" +
+	"\x05is_up\x18\x02 \x01(\bR\x04isUp\x12\x14\n" +
+	"\x05state\x18\x03 \x01(\tR\x05state\x12(\n" +
+	"\x10response_time_ms\x18\x04 \x01(\x03R\x0eresponseTimeMs\x12(\n" +
+	"\x10response_time_us\x18\x05 \x01(\x03R\x0eresponseTimeUs\x12\x1f\n" +
+	"\vstatus_code\x18\x06 \x01(\x05R\n" +
+	"statusCode\x12=\n" +
+	"\flast_checked\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\vlastChecked\x12\x14\n" +
+	"\x05error\x18\b \x01(\tR\x05error\x12\x1a\n" +
+	"\bprotocol\x18\t \x01(\tR\bprotocol\x12\x1a\n" +
+	"\blocation\x18\n" +
+	" \x01(\tR\blocation\x12\x1d\n" +
+	"\n" +
+	"bytes_read\x18\v \x01(\x03R\tbytesRead\"\x12\n" +
+	"\x10GetStatusRequest\"K\n" +
+	"\x11GetStatusResponse\x126\n" +
+	"\bstatuses\x18\x01 \x03(\v2\x1a.sitemonitor.v1.SiteStatusR\bstatuses\"&\n" +
+	"\x14GetStatusByIDRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"\x14\n" +
+	"\x12WatchStatusRequest2\x86\x02\n" +
+	"\aMonitor\x12P\n" +
+	"\tGetStatus\x12 .sitemonitor.v1.GetStatusRequest\x1a!.sitemonitor.v1.GetStatusResponse\x12Q\n" +
+	"\rGetStatusByID\x12$.sitemonitor.v1.GetStatusByIDRequest\x1a\x1a.sitemonitor.v1.SiteStatus\x12V\n" +
+	"\vWatchStatus\x12\".sitemonitor.v1.WatchStatusRequest\x1a!.sitemonitor.v1.GetStatusResponse0\x01B\x18Z\x16site-monitor/monitorpbb\x06proto3"
+
+var (
+	file_monitorpb_monitor_proto_rawDescOnce sync.Once
+	file_monitorpb_monitor_proto_rawDescData []byte
+)
+
+func file_monitorpb_monitor_proto_rawDescGZIP() []byte {
+	file_monitorpb_monitor_proto_rawDescOnce.Do(func() {
+		file_monitorpb_monitor_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_monitorpb_monitor_proto_rawDesc), len(file_monitorpb_monitor_proto_rawDesc)))
+	})
+	return file_monitorpb_monitor_proto_rawDescData
+}
+
+var file_monitorpb_monitor_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
+var file_monitorpb_monitor_proto_goTypes = []any{
+	(*Site)(nil),                  // 0: sitemonitor.v1.Site
+	(*SiteStatus)(nil),            // 1: sitemonitor.v1.SiteStatus
+	(*GetStatusRequest)(nil),      // 2: sitemonitor.v1.GetStatusRequest
+	(*GetStatusResponse)(nil),     // 3: sitemonitor.v1.GetStatusResponse
+	(*GetStatusByIDRequest)(nil),  // 4: sitemonitor.v1.GetStatusByIDRequest
+	(*WatchStatusRequest)(nil),    // 5: sitemonitor.v1.WatchStatusRequest
+	(*timestamppb.Timestamp)(nil), // 6: google.protobuf.Timestamp
+}
+var file_monitorpb_monitor_proto_depIdxs = []int32{
+	0, // 0: sitemonitor.v1.SiteStatus.site:type_name -> sitemonitor.v1.Site
+	6, // 1: sitemonitor.v1.SiteStatus.last_checked:type_name -> google.protobuf.Timestamp
+	1, // 2: sitemonitor.v1.GetStatusResponse.statuses:type_name -> sitemonitor.v1.SiteStatus
+	2, // 3: sitemonitor.v1.Monitor.GetStatus:input_type -> sitemonitor.v1.GetStatusRequest
+	4, // 4: sitemonitor.v1.Monitor.GetStatusByID:input_type -> sitemonitor.v1.GetStatusByIDRequest
+	5, // 5: sitemonitor.v1.Monitor.WatchStatus:input_type -> sitemonitor.v1.WatchStatusRequest
+	3, // 6: sitemonitor.v1.Monitor.GetStatus:output_type -> sitemonitor.v1.GetStatusResponse
+	1, // 7: sitemonitor.v1.Monitor.GetStatusByID:output_type -> sitemonitor.v1.SiteStatus
+	3, // 8: sitemonitor.v1.Monitor.WatchStatus:output_type -> sitemonitor.v1.GetStatusResponse
+	6, // [6:9] is the sub-list for method output_type
+	3, // [3:6] is the sub-list for method input_type
+	3, // [3:3] is the sub-list for extension type_name
+	3, // [3:3] is the sub-list for extension extendee
+	0, // [0:3] is the sub-list for field type_name
+}
+
+func init() { file_monitorpb_monitor_proto_init() }
+func file_monitorpb_monitor_proto_init() {
+	if File_monitorpb_monitor_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_monitorpb_monitor_proto_rawDesc), len(file_monitorpb_monitor_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   6,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_monitorpb_monitor_proto_goTypes,
+		DependencyIndexes: file_monitorpb_monitor_proto_depIdxs,
+		MessageInfos:      file_monitorpb_monitor_proto_msgTypes,
+	}.Build()
+	File_monitorpb_monitor_proto = out.File
+	file_monitorpb_monitor_proto_goTypes = nil
+	file_monitorpb_monitor_proto_depIdxs = nil
+}
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// Transition décrit un changement d'état d'un site (up, degraded, down...)
+type Transition struct {
+	PreviousIsUp  bool
+	PreviousState string
+	Status        SiteStatus
+	At            time.Time
+}
+
+// Canaux de notification configurés au démarrage
+var (
+	slackWebhookURL string
+	webhookURL      string
+)
+
+// notifyClient est partagé par les envois ; son timeout borne chaque notification
+var notifyClient = &http.Client{Timeout: 10 * time.Second}
+
+// loadNotifyConfig lit la configuration des canaux de notification
+func loadNotifyConfig() {
+	slackWebhookURL = os.Getenv("SLACK_WEBHOOK_URL")
+	if slackWebhookURL != "" {
+		logInfo("🔔 Notifications Slack activées")
+	}
+	webhookURL = os.Getenv("WEBHOOK_URL")
+	if webhookURL != "" {
+		logInfo("🔔 Webhook de transitions activé")
+	}
+}
+
+// detectTransition compare l'ancien et le nouveau statut d'un site.
+// Le premier résultat après le démarrage (état pending) n'est pas une transition.
+func detectTransition(prev, next SiteStatus) (Transition, bool) {
+	if prev.State == statePending || prev.State == next.State {
+		return Transition{}, false
+	}
+	return Transition{PreviousIsUp: prev.IsUp, PreviousState: prev.State, Status: next, At: next.LastChecked}, true
+}
+
+// statusChange associe le nouveau statut d'un site au précédent
+type statusChange struct {
+	Previous SiteStatus
+	Current  SiteStatus
+}
+
+// dispatchNotifications envoie les notifications d'une passe, sans bloquer celle-ci.
+// Aucune alerte n'est envoyée pendant la période de démarrage (WARMUP_SECONDS).
+// Si le moniteur semble isolé, les alertes individuelles sont supprimées :
+// une seule alerte d'isolation est envoyée par notifyIsolation. De même, un site instable
+// ne donne lieu qu'à une alerte à l'entrée et à la sortie de l'instabilité. Les seuils par type
+// d'erreur (ALERT_THRESHOLDS) peuvent retarder l'alerte d'une panne, voir gateAlert.
+func dispatchNotifications(changes []statusChange, isolated bool) {
+	for _, c := range changes {
+		checkCertExpiry(c.Current)
+
+		t, isTransition := detectTransition(c.Previous, c.Current)
+		// Un site toujours en panne à la fin de sa maintenance ou de la période de démarrage
+		// est signalé comme une nouvelle panne
+		endOfQuiet := c.Previous.Maintenance && !c.Current.Maintenance || c.Previous.warmup && !c.Current.warmup
+		if !isTransition && endOfQuiet && c.Current.State != stateUp {
+			t = Transition{PreviousIsUp: c.Previous.IsUp, PreviousState: c.Previous.State, Status: c.Current, At: c.Current.LastChecked}
+			isTransition = true
+		}
+		t, isTransition = gateAlert(c, t, isTransition)
+		mail, isMail := emailEventFor(c)
+		flapChanged := c.Current.Flapping != c.Previous.Flapping
+		if !isTransition && !isMail && !flapChanged {
+			continue
+		}
+		if c.Current.Maintenance {
+			logEvent("info", siteFields(c.Current.Site), "🔧 Alerte supprimée pour %s : maintenance en cours", c.Current.Site.Name)
+			continue
+		}
+		if c.Current.warmup {
+			logEvent("info", siteFields(c.Current.Site), "🌡️ Alerte supprimée pour %s : période de démarrage", c.Current.Site.Name)
+			continue
+		}
+		if isolated {
+			logEvent("info", siteFields(c.Current.Site), "🔕 Alerte supprimée pour %s : moniteur isolé", c.Current.Site.Name)
+			continue
+		}
+		if flapChanged {
+			notifyFlapping(c.Current)
+		}
+		if c.Current.Flapping {
+			if !flapChanged {
+				logEvent("info", siteFields(c.Current.Site), "〰️ Alerte supprimée pour %s : site instable", c.Current.Site.Name)
+			}
+			continue
+		}
+
+		if isTransition {
+			if slackWebhookURL != "" {
+				goNotify("slack", func() { sendSlack(formatSlackTransition(t)) })
+			}
+			if webhookURL != "" {
+				goNotify("webhook", func() { sendWebhook(t) })
+			}
+		}
+		if isMail {
+			goNotify("email", func() { sendEmail(mail) })
+		}
+	}
+}
+
+// notifyIsolation signale le début ou la fin d'une isolation réseau du moniteur
+func notifyIsolation(isolated bool) {
+	if slackWebhookURL == "" {
+		return
+	}
+	text := "🌐 Connectivité du moniteur rétablie, reprise des alertes individuelles"
+	if isolated {
+		text = fmt.Sprintf("🌐 *Le moniteur est peut-être isolé du réseau* : au moins %.0f%% des sites sont en panne simultanément. Les alertes individuelles sont suspendues.", isolationRatio*100)
+	}
+	goNotify("slack", func() { sendSlack(text) })
+}
+
+// formatSlackTransition construit le message Slack d'une transition
+func formatSlackTransition(t Transition) string {
+	st := t.Status
+	var b strings.Builder
+	switch st.State {
+	case stateUp:
+		fmt.Fprintf(&b, "🟢 *%s* est de nouveau UP\n", st.Site.Name)
+	case stateDegraded:
+		fmt.Fprintf(&b, "🟡 *%s* est DÉGRADÉ (répond lentement)\n", st.Site.Name)
+	default:
+		fmt.Fprintf(&b, "🔴 *%s* est DOWN\n", st.Site.Name)
+	}
+	fmt.Fprintf(&b, "URL : %s\nCode : %d\nTemps de réponse : %dms\n", redactURLPassword(st.Site.URL), st.StatusCode, st.ResponseTime)
+	if st.Error != "" {
+		fmt.Fprintf(&b, "Erreur : %s\n", st.Error)
+	}
+	if st.Site.Description != "" {
+		fmt.Fprintf(&b, "Description : %s\n", st.Site.Description)
+	}
+	if st.Site.Runbook != "" {
+		fmt.Fprintf(&b, "Runbook : %s\n", st.Site.Runbook)
+	}
+	return b.String()
+}
+
+// sendSlack poste un message sur le webhook entrant Slack
+func sendSlack(text string) {
+	payload, _ := json.Marshal(map[string]string{"text": text})
+	if err := postJSON(notifyClient, slackWebhookURL, payload); err != nil {
+		logWarn("⚠️ Notification Slack échouée : %v", err)
+	}
+}
+
+// webhookPayload est le corps JSON envoyé à WEBHOOK_URL : le SiteStatus complet,
+// l'état précédent et l'instant de la transition
+type webhookPayload struct {
+	SiteStatus
+	PreviousIsUp  bool      `json:"previous_is_up"`
+	PreviousState string    `json:"previous_state"`
+	TransitionAt  time.Time `json:"transition_at"`
+}
+
+// webhookClient applique le délai de 5 secondes propre au webhook générique
+var webhookClient = &http.Client{Timeout: 5 * time.Second}
+
+// sendWebhook livre une transition au webhook générique, avec une seule nouvelle tentative.
+// La livraison est best-effort : un échec est journalisé et abandonné.
+func sendWebhook(t Transition) {
+	payload, err := json.Marshal(webhookPayload{
+		SiteStatus:    t.Status,
+		PreviousIsUp:  t.PreviousIsUp,
+		PreviousState: t.PreviousState,
+		TransitionAt:  t.At.UTC(),
+	})
+	if err != nil {
+		logWarn("⚠️ Webhook : encodage impossible : %v", err)
+		return
+	}
+
+	for attempt := 1; attempt <= 2; attempt++ {
+		err = postJSON(webhookClient, webhookURL, payload)
+		if err == nil {
+			return
+		}
+		if attempt == 1 {
+			time.Sleep(time.Second)
+		}
+	}
+	logEvent("warn", siteFields(t.Status.Site), "⚠️ Webhook non délivré pour %s : %v", t.Status.Site.Name, err)
+}
+
+// postJSON envoie un corps JSON et considère tout code >= 300 comme un échec
+func postJSON(client *http.Client, url string, payload []byte) error {
+	resp, err := client.Post(url, "application/json", bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("code %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// readSitesConfig lit le fichier de base et, si ENV est défini, fusionne par-dessus
+// l'overlay correspondant (ENV=prod : sites.json + sites.prod.json, sites.yaml + sites.prod.yaml).
+// Le résultat est un document JSON sans commentaires prêt à être décodé en []Site.
+func readSitesConfig(path string) ([]byte, error) {
+	base, err := readSiteObjects(path)
+	if err != nil {
+		return nil, err
+	}
+
+	env := os.Getenv("ENV")
+	if env == "" {
+		return json.Marshal(base)
+	}
+
+	overlayPath := overlayPathFor(path, env)
+	overlay, err := readSiteObjects(overlayPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		logWarn("⚠️ ENV=%s mais aucun overlay %s, configuration de base utilisée", env, overlayPath)
+		return json.Marshal(base)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	merged, err := mergeSiteObjects(base, overlay)
+	if err != nil {
+		return nil, fmt.Errorf("%s : %w", overlayPath, err)
+	}
+	logInfo("🧩 Overlay %s appliqué", overlayPath)
+	return json.Marshal(merged)
+}
+
+// overlayPathFor renvoie le chemin de l'overlay d'un environnement (sites.json → sites.prod.json)
+func overlayPathFor(path, env string) string {
+	ext := filepath.Ext(path)
+	return strings.TrimSuffix(path, ext) + "." + env + ext
+}
+
+// readSiteObjects lit un fichier de sites (JSON ou YAML selon l'extension) en gardant chaque entrée sous forme de champs bruts
+func readSiteObjects(path string) ([]map[string]json.RawMessage, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if isYAMLPath(path) {
+		return decodeYAMLSiteObjects(path, data)
+	}
+	var objs []map[string]json.RawMessage
+	if err := json.Unmarshal(stripJSONComments(data), &objs); err != nil {
+		return nil, fmt.Errorf("%s : %w", path, err)
+	}
+	return objs, nil
+}
+
+// mergeSiteObjects applique un overlay sur la configuration de base, site par site (clé "id").
+// Un champ présent dans l'overlay remplace celui de la base ; "remove": true supprime le site ;
+// un ID inconnu de la base ajoute le site. L'ordre de la base est conservé et les ajouts
+// suivent, dans l'ordre de l'overlay, pour que le résultat soit déterministe.
+func mergeSiteObjects(base, overlay []map[string]json.RawMessage) ([]map[string]json.RawMessage, error) {
+	index := make(map[string]int, len(base))
+	for i, obj := range base {
+		index[objectID(obj)] = i
+	}
+
+	removed := make(map[int]bool)
+	var added []map[string]json.RawMessage
+
+	for _, obj := range overlay {
+		id := objectID(obj)
+		if id == "" {
+			return nil, errors.New("entrée d'overlay sans id")
+		}
+
+		var remove bool
+		if raw, ok := obj["remove"]; ok {
+			if err := json.Unmarshal(raw, &remove); err != nil {
+				return nil, fmt.Errorf("site %s : champ remove invalide : %w", id, err)
+			}
+			delete(obj, "remove")
+		}
+
+		i, exists := index[id]
+		switch {
+		case remove && exists:
+			removed[i] = true
+		case remove:
+			logWarn("⚠️ Overlay : suppression du site %s inconnu ignorée", id)
+		case exists:
+			for k, v := range obj {
+				base[i][k] = v
+			}
+		default:
+			added = append(added, obj)
+		}
+	}
+
+	merged := make([]map[string]json.RawMessage, 0, len(base)+len(added))
+	for i, obj := range base {
+		if !removed[i] {
+			merged = append(merged, obj)
+		}
+	}
+	return append(merged, added...), nil
+}
+
+// objectID extrait l'id d'une entrée brute (chaîne vide si absent)
+func objectID(obj map[string]json.RawMessage) string {
+	var id string
+	json.Unmarshal(obj["id"], &id)
+	return id
+}
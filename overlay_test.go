@@ -0,0 +1,64 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestMergeSiteObjects(t *testing.T) {
+	const base = `[{"id": "a", "url": "http://a", "interval": "30s"}, {"id": "b", "url": "http://b"}]`
+	tests := []struct {
+		name    string
+		overlay string
+		want    string
+		wantErr bool
+	}{
+		{"overlay vide", `[]`, base, false},
+		{"champ remplacé, les autres gardés", `[{"id": "a", "url": "http://a.prod"}]`,
+			`[{"id": "a", "url": "http://a.prod", "interval": "30s"}, {"id": "b", "url": "http://b"}]`, false},
+		{"suppression", `[{"id": "a", "remove": true}]`, `[{"id": "b", "url": "http://b"}]`, false},
+		{"suppression d'un site inconnu ignorée", `[{"id": "z", "remove": true}]`, base, false},
+		{"ajouts après la base, dans l'ordre de l'overlay", `[{"id": "d", "url": "http://d"}, {"id": "c", "url": "http://c"}]`,
+			`[{"id": "a", "url": "http://a", "interval": "30s"}, {"id": "b", "url": "http://b"}, {"id": "d", "url": "http://d"}, {"id": "c", "url": "http://c"}]`, false},
+		{"remove: false ne supprime pas", `[{"id": "b", "remove": false, "name": "B"}]`,
+			`[{"id": "a", "url": "http://a", "interval": "30s"}, {"id": "b", "url": "http://b", "name": "B"}]`, false},
+		{"entrée sans id", `[{"url": "http://x"}]`, "", true},
+		{"remove invalide", `[{"id": "a", "remove": "oui"}]`, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b, o []map[string]json.RawMessage
+			if err := json.Unmarshal([]byte(base), &b); err != nil {
+				t.Fatal(err)
+			}
+			if err := json.Unmarshal([]byte(tt.overlay), &o); err != nil {
+				t.Fatal(err)
+			}
+			merged, err := mergeSiteObjects(b, o)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("erreur %v, attendue : %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			data, err := json.Marshal(merged)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got, want := canonicalJSON(t, data), canonicalJSON(t, []byte(tt.want)); got != want {
+				t.Errorf("fusion = %s, attendu %s", got, want)
+			}
+		})
+	}
+}
+
+// canonicalJSON réencode un document JSON avec des clés triées pour comparer deux listes de sites
+func canonicalJSON(t *testing.T, data []byte) string {
+	t.Helper()
+	var v any
+	if err := json.Unmarshal(data, &v); err != nil {
+		t.Fatal(err)
+	}
+	out, _ := json.Marshal(v)
+	return string(out)
+}
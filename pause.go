@@ -0,0 +1,104 @@
+package main
+
+import (
+	"net/http"
+)
+
+// statePaused est l'état affiché d'un site en pause : il n'est plus vérifié
+// et son dernier résultat reste figé
+const statePaused = "paused"
+
+// pauseOverrides retient les pauses et reprises demandées via l'API, pour qu'un rechargement
+// de la configuration ne les annule pas (protégé par statusMutex)
+var pauseOverrides = make(map[string]bool)
+
+// syncPauseState aligne l'état affiché sur Site.Paused. L'état d'avant la pause est conservé
+// pour être rétabli à la reprise ; inconnu (après un redémarrage), le site repasse en attente.
+func syncPauseState(st SiteStatus) SiteStatus {
+	switch {
+	case st.Site.Paused && st.State != statePaused:
+		st.pausedFrom = st.State
+		st.State = statePaused
+	case !st.Site.Paused && st.State == statePaused:
+		st.State = st.pausedFrom
+		if st.State == "" {
+			st.State = statePending
+		}
+		st.pausedFrom = ""
+	}
+	return st
+}
+
+// applyPauseOverrides reporte sur une configuration rechargée les pauses posées via l'API
+// (l'appelant doit détenir statusMutex)
+func applyPauseOverrides(list []Site) {
+	for i, s := range list {
+		if paused, ok := pauseOverrides[s.ID]; ok {
+			list[i].Paused = paused
+		}
+	}
+}
+
+// activeSites renvoie les sites qui ne sont pas en pause
+func activeSites(list []Site) []Site {
+	active := make([]Site, 0, len(list))
+	for _, s := range list {
+		if !s.Paused {
+			active = append(active, s)
+		}
+	}
+	return active
+}
+
+// handlePauseSite suspend la vérification d'un site sans le retirer de la configuration
+func handlePauseSite(w http.ResponseWriter, r *http.Request) {
+	setPaused(w, r, true)
+}
+
+// handleResumeSite reprend la vérification d'un site, dès la prochaine passe
+func handleResumeSite(w http.ResponseWriter, r *http.Request) {
+	setPaused(w, r, false)
+}
+
+// setPaused met un site en pause ou le reprend et renvoie son statut
+func setPaused(w http.ResponseWriter, r *http.Request, paused bool) {
+	id := r.PathValue("id")
+
+	statusMutex.Lock()
+	if _, exists := findSite(id); !exists {
+		statusMutex.Unlock()
+		http.Error(w, "Site introuvable", http.StatusNotFound)
+		return
+	}
+	pauseOverrides[id] = paused
+	newSites := append([]Site(nil), sites...)
+	for i := range newSites {
+		if newSites[i].ID == id {
+			newSites[i].Paused = paused
+		}
+	}
+	newStatuses := append([]SiteStatus(nil), statuses...)
+	var result SiteStatus
+	for i := range newStatuses {
+		if newStatuses[i].Site.ID == id {
+			newStatuses[i].Site.Paused = paused
+			newStatuses[i] = syncPauseState(newStatuses[i])
+			result = newStatuses[i]
+		}
+	}
+	sites, statuses = newSites, newStatuses
+	statusMutex.Unlock()
+	publishStatuses(newStatuses)
+
+	if paused {
+		logEvent("info", siteFields(result.Site), "⏸️ Surveillance de %s suspendue", result.Site.Name)
+		recordAudit(r, auditPause, id, "")
+	} else {
+		logEvent("info", siteFields(result.Site), "▶️ Surveillance de %s reprise", result.Site.Name)
+		rescheduleSite(id)
+		recordAudit(r, auditResume, id, "")
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	writeJSON(w, r, result)
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"golang.org/x/net/proxy"
+)
+
+// proxyEnvVars sont les variables lues par http.ProxyFromEnvironment pour le transport partagé
+var proxyEnvVars = []string{"HTTP_PROXY", "http_proxy", "HTTPS_PROXY", "https_proxy"}
+
+// loadProxyConfig valide au démarrage les proxys globaux (HTTP_PROXY, HTTPS_PROXY, NO_PROXY),
+// qui sinon ne seraient rejetés qu'à la première vérification
+func loadProxyConfig() {
+	for _, key := range proxyEnvVars {
+		v := os.Getenv(key)
+		if v == "" {
+			continue
+		}
+		if _, err := parseProxyURL(v); err != nil {
+			logFatal("❌ %s invalide : %v", key, err)
+		}
+		logInfo("🧭 Vérifications routées via le proxy %s (%s)", redactProxy(v), key)
+	}
+}
+
+// parseProxyURL accepte les proxys http, https et socks5 (socks5h : résolution DNS par le proxy)
+func parseProxyURL(raw string) (*url.URL, error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		// url.Error recopie l'URL en clair : seule la cause est conservée
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return nil, fmt.Errorf("proxy %q invalide : %w", redactProxy(raw), err)
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return nil, fmt.Errorf("proxy %q : schéma %q non supporté (attendu : http, https, socks5 ou socks5h)", redactProxy(raw), u.Scheme)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("proxy %q sans hôte", redactProxy(raw))
+	}
+	return u, nil
+}
+
+// redactProxy masque l'éventuel mot de passe d'une URL de proxy avant de la journaliser.
+// Une URL illisible perd toute la partie user:pass@ qui précède l'hôte.
+func redactProxy(raw string) string {
+	if u, err := url.Parse(raw); err == nil {
+		return u.Redacted()
+	}
+	rest, scheme := raw, ""
+	if i := strings.Index(raw, "://"); i >= 0 {
+		scheme, rest = raw[:i+3], raw[i+3:]
+	}
+	authority := rest
+	if i := strings.IndexAny(rest, "/?#"); i >= 0 {
+		authority = rest[:i]
+	}
+	if i := strings.LastIndex(authority, "@"); i >= 0 {
+		return scheme + redactedSecret + rest[i:]
+	}
+	return raw
+}
+
+// proxyError distingue un échec de connexion au proxy d'une panne du site lui-même.
+// Le transport enveloppe ces échecs (proxy injoignable, CONNECT ou négociation SOCKS refusés)
+// dans une net.OpError "proxyconnect".
+func proxyError(t *http.Transport, req *http.Request, err error) (string, bool) {
+	var opErr *net.OpError
+	if req == nil || t.Proxy == nil || !errors.As(err, &opErr) || opErr.Op != "proxyconnect" {
+		return "", false
+	}
+	proxy, perr := t.Proxy(req)
+	if perr != nil || proxy == nil {
+		return "", false
+	}
+	return fmt.Sprintf("échec de connexion via le proxy %s : %v", proxy.Redacted(), opErr.Err), true
+}
+
+// siteProxy renvoie le proxy à emprunter pour joindre addr hors transport HTTP (vérifications gRPC) :
+// celui du site, sinon HTTP_PROXY/HTTPS_PROXY selon useTLS, NO_PROXY compris ; nil pour une connexion directe
+func siteProxy(site Site, addr string, useTLS bool) *url.URL {
+	if site.Proxy != "" {
+		// Déjà validée avec la configuration
+		u, _ := parseProxyURL(site.Proxy)
+		return u
+	}
+	scheme := "http"
+	if useTLS {
+		scheme = "https"
+	}
+	u, err := http.ProxyFromEnvironment(&http.Request{URL: &url.URL{Scheme: scheme, Host: addr}})
+	if err != nil {
+		return nil
+	}
+	return u
+}
+
+// dialViaProxy ouvre une connexion vers addr à travers le proxy u : négociation SOCKS5 pour
+// socks5(h)://, tunnel CONNECT (avec Proxy-Authorization si l'URL porte user:pass@) sinon.
+// Les échecs côté proxy sont signalés comme tels, à la manière de proxyError.
+func dialViaProxy(ctx context.Context, dialer *net.Dialer, network string, u *url.URL, addr string) (net.Conn, error) {
+	conn, err := dialProxyTunnel(ctx, dialer, network, u, addr)
+	if err != nil {
+		return nil, fmt.Errorf("échec de connexion via le proxy %s : %w", u.Redacted(), err)
+	}
+	return conn, nil
+}
+
+func dialProxyTunnel(ctx context.Context, dialer *net.Dialer, network string, u *url.URL, addr string) (net.Conn, error) {
+	if u.Scheme == "socks5" || u.Scheme == "socks5h" {
+		d, err := proxy.FromURL(u, dialer)
+		if err != nil {
+			return nil, err
+		}
+		return d.(proxy.ContextDialer).DialContext(ctx, network, addr)
+	}
+
+	proxyAddr := u.Host
+	if u.Port() == "" {
+		port := "80"
+		if u.Scheme == "https" {
+			port = "443"
+		}
+		proxyAddr = net.JoinHostPort(u.Hostname(), port)
+	}
+	conn, err := dialer.DialContext(ctx, network, proxyAddr)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme == "https" {
+		tlsConn := tls.Client(conn, &tls.Config{ServerName: u.Hostname()})
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			conn.Close()
+			return nil, err
+		}
+		conn = tlsConn
+	}
+
+	req := &http.Request{Method: http.MethodConnect, URL: &url.URL{Opaque: addr}, Host: addr, Header: make(http.Header)}
+	if u.User != nil {
+		pass, _ := u.User.Password()
+		req.Header.Set("Proxy-Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(u.User.Username()+":"+pass)))
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	br := bufio.NewReader(conn)
+	if err := req.Write(conn); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	resp, err := http.ReadResponse(br, req)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		conn.Close()
+		return nil, fmt.Errorf("CONNECT refusé : %s", resp.Status)
+	}
+	conn.SetDeadline(time.Time{})
+	// Le serveur visé a pu parler dès l'ouverture du tunnel : ces octets sont déjà dans br
+	return &bufferedConn{Conn: conn, r: br}, nil
+}
+
+// bufferedConn relit d'abord les octets déjà mis en tampon lors de la négociation CONNECT
+type bufferedConn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+func (c *bufferedConn) Read(p []byte) (int, error) {
+	return c.r.Read(p)
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// tokenBucket est le seau de jetons d'un client : il se remplit de rate jetons par seconde,
+// jusqu'à burst, et chaque requête en consomme un
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// rateLimiter répartit les seaux par adresse IP cliente
+type rateLimiter struct {
+	rate       float64
+	burst      float64
+	trustProxy bool
+
+	mu        sync.Mutex
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+}
+
+// bucketIdleTTL : un seau inutilisé depuis ce délai est plein de toute façon, on l'oublie
+const bucketIdleTTL = 10 * time.Minute
+
+// allow consomme un jeton pour ip ; sinon renvoie le délai avant le prochain jeton
+func (l *rateLimiter) allow(ip string, now time.Time) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if now.Sub(l.lastSweep) > time.Minute {
+		for k, b := range l.buckets {
+			if now.Sub(b.last) > bucketIdleTTL {
+				delete(l.buckets, k)
+			}
+		}
+		l.lastSweep = now
+	}
+
+	b, ok := l.buckets[ip]
+	if !ok {
+		b = &tokenBucket{tokens: l.burst, last: now}
+		l.buckets[ip] = b
+	}
+	b.tokens = math.Min(l.burst, b.tokens+now.Sub(b.last).Seconds()*l.rate)
+	b.last = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+	wait := time.Duration((1 - b.tokens) / l.rate * float64(time.Second))
+	return false, wait
+}
+
+// clientIP renvoie l'adresse du client ; X-Forwarded-For n'est lu que derrière un proxy de confiance
+func (l *rateLimiter) clientIP(r *http.Request) string {
+	return requestClientIP(r, l.trustProxy)
+}
+
+// requestClientIP renvoie l'adresse du client d'une requête, lue dans X-Forwarded-For si trustProxy
+func requestClientIP(r *http.Request, trustProxy bool) string {
+	if trustProxy {
+		if fwd := r.Header.Get("X-Forwarded-For"); fwd != "" {
+			first, _, _ := strings.Cut(fwd, ",")
+			return strings.TrimSpace(first)
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// rateLimitMiddleware limite le débit par IP (RATE_LIMIT_RPS, 20/s par défaut, 0 désactive ;
+// RATE_LIMIT_BURST, 40 par défaut ; RATE_LIMIT_TRUST_PROXY pour lire X-Forwarded-For).
+// Une requête au-delà de la limite reçoit un 429 avec Retry-After.
+func rateLimitMiddleware(next http.Handler) http.Handler {
+	rate := envFloat("RATE_LIMIT_RPS", 20)
+	if rate == 0 {
+		return next
+	}
+	l := &rateLimiter{
+		rate:       rate,
+		burst:      float64(max(envInt("RATE_LIMIT_BURST", 40), 1)),
+		trustProxy: envBool("RATE_LIMIT_TRUST_PROXY", false),
+		buckets:    make(map[string]*tokenBucket),
+	}
+	logInfo("🚦 Limite de débit : %g requête(s)/s par client (rafale %g)", l.rate, l.burst)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ok, wait := l.allow(l.clientIP(r), time.Now())
+		if !ok {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+			http.Error(w, "Trop de requêtes, réessayez plus tard", http.StatusTooManyRequests)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
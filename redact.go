@@ -0,0 +1,113 @@
+package main
+
+import (
+	"encoding/json"
+	"net/url"
+	"strings"
+)
+
+// redactedSecret remplace un secret dans toutes les sorties JSON (API, webhook, fichier d'état)
+const redactedSecret = "********"
+
+// credentialHeaders en-têtes dont la valeur est toujours masquée ; les en-têtes dont le nom
+// contient un des marqueurs de credentialHeaderHints le sont aussi (X-API-Key, X-Auth-Token...)
+var (
+	credentialHeaders     = []string{"authorization", "proxy-authorization", "cookie"}
+	credentialHeaderHints = []string{"token", "secret", "key", "auth", "password", "session", "signature"}
+)
+
+// MarshalJSON sérialise le site avec ses identifiants masqués : toute sortie (API, webhook,
+// SSE, fichier d'état) passe par redactSite
+func (s Site) MarshalJSON() ([]byte, error) {
+	// Le type intermédiaire n'a pas de méthode MarshalJSON, ce qui évite la récursion
+	type siteJSON Site
+	return json.Marshal(siteJSON(redactSite(s)))
+}
+
+// redactSite renvoie une copie du site dont les champs porteurs d'identifiants sont masqués :
+// mot de passe Basic, valeurs des en-têtes d'authentification, mots de passe des URL (site,
+// endpoints) et du proxy
+func redactSite(s Site) Site {
+	s.URL = redactURLPassword(s.URL)
+	if len(s.Endpoints) > 0 {
+		endpoints := make([]string, len(s.Endpoints))
+		for i, endpoint := range s.Endpoints {
+			endpoints[i] = redactURLPassword(endpoint)
+		}
+		s.Endpoints = endpoints
+	}
+	if s.Password != "" {
+		s.Password = redactedSecret
+	}
+	if len(s.Headers) > 0 {
+		headers := make(map[string]string, len(s.Headers))
+		for name, value := range s.Headers {
+			if isCredentialHeader(name) {
+				value = redactedSecret
+			}
+			headers[name] = value
+		}
+		s.Headers = headers
+	}
+	s.Proxy = redactURLPassword(s.Proxy)
+	return s
+}
+
+// isCredentialHeader indique si la valeur d'un en-tête est probablement un identifiant
+func isCredentialHeader(name string) bool {
+	name = strings.ToLower(name)
+	for _, h := range credentialHeaders {
+		if name == h {
+			return true
+		}
+	}
+	for _, hint := range credentialHeaderHints {
+		if strings.Contains(name, hint) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactURLPassword masque le mot de passe d'une URL (user:pass@hôte) ; une URL sans mot de passe
+// est renvoyée telle quelle
+func redactURLPassword(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil || u.User == nil {
+		return raw
+	}
+	if _, ok := u.User.Password(); !ok {
+		return raw
+	}
+	// Redacted masque par "xxxxx" ; UserPassword échapperait les astérisques du masque commun
+	return strings.Replace(u.Redacted(), ":xxxxx@", ":"+redactedSecret+"@", 1)
+}
+
+// keepRedactedSecrets restaure les identifiants actuels quand une définition envoyée via l'API
+// reprend les masques obtenus d'un GET (export puis réimport de la configuration)
+func keepRedactedSecrets(next Site, current Site, exists bool) Site {
+	if !exists {
+		return next
+	}
+	if next.Password == redactedSecret {
+		next.Password = current.Password
+	}
+	for name, value := range next.Headers {
+		if v, ok := current.Headers[name]; ok && value == redactedSecret && isCredentialHeader(name) {
+			next.Headers[name] = v
+		}
+	}
+	if next.URL != current.URL && next.URL == redactURLPassword(current.URL) {
+		next.URL = current.URL
+	}
+	// Endpoints comparés position par position : un masque ne vaut que pour l'URL qu'il remplace
+	for i, endpoint := range next.Endpoints {
+		if i < len(current.Endpoints) && endpoint != current.Endpoints[i] && endpoint == redactURLPassword(current.Endpoints[i]) {
+			next.Endpoints[i] = current.Endpoints[i]
+		}
+	}
+	if next.Proxy != current.Proxy && next.Proxy == redactURLPassword(current.Proxy) {
+		next.Proxy = current.Proxy
+	}
+	return next
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"reflect"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// sitesConfigPath est le fichier de configuration des sites, relu à chaud quand il change
+// (JSON ou YAML, voir resolveSitesConfigPath)
+var sitesConfigPath = resolveSitesConfigPath()
+
+// fileStamp identifie une version d'un fichier (absent : zéro)
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// configStamps renvoie l'empreinte du fichier de base et de l'overlay ENV éventuel
+func configStamps(path string) []fileStamp {
+	paths := []string{path}
+	if env := os.Getenv("ENV"); env != "" {
+		paths = append(paths, overlayPathFor(path, env))
+	}
+	stamps := make([]fileStamp, len(paths))
+	for i, p := range paths {
+		if info, err := os.Stat(p); err == nil {
+			stamps[i] = fileStamp{modTime: info.ModTime(), size: info.Size()}
+		}
+	}
+	return stamps
+}
+
+// watchSitesConfig surveille la configuration (CONFIG_RELOAD_SECONDS, 5 par défaut ; 0 désactive)
+// et l'applique dès qu'elle change. Une configuration invalide est ignorée : l'ancienne reste active.
+func watchSitesConfig(ctx context.Context, path string) {
+	seconds := envInt("CONFIG_RELOAD_SECONDS", 5)
+	if seconds == 0 {
+		return
+	}
+
+	last := configStamps(path)
+	ticker := time.NewTicker(time.Duration(seconds) * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			stamps := configStamps(path)
+			if reflect.DeepEqual(stamps, last) {
+				continue
+			}
+			last = stamps
+			reloadSites(path)
+		}
+	}
+}
+
+// watchSIGHUP recharge la configuration à chaque SIGHUP (kill -HUP), sans attendre la surveillance du fichier
+func watchSIGHUP(ctx context.Context, path string) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			logInfo("🔔 SIGHUP reçu, rechargement de %s", path)
+			reloadSites(path)
+		}
+	}
+}
+
+// reloadSites relit et applique la configuration ; invalide, elle est ignorée et l'ancienne reste active
+func reloadSites(path string) {
+	list, err := parseSites(path)
+	if err != nil {
+		logError("❌ Configuration %s invalide, ancienne configuration conservée : %v", path, err)
+		return
+	}
+	applySites(list, false)
+}
+
+// siteChanges liste les ids concernés par un remplacement de la liste des sites
+type siteChanges struct {
+	added, changed, removed []string
+}
+
+// applySites remplace la liste des sites en conservant le statut des sites qui restent
+// et renvoie les sites ajoutés, modifiés et supprimés.
+// Les sites nouveaux ou modifiés sont vérifiés dès la prochaine passe.
+// Un rechargement du fichier conserve les pauses décidées via l'API ; une liste envoyée
+// via l'API (fromAPI) fait foi, y compris pour paused.
+func applySites(list []Site, fromAPI bool) siteChanges {
+	statusMutex.Lock()
+	changes := replaceSites(list, fromAPI)
+	statusMutex.Unlock()
+	finishReplaceSites(list, changes, fromAPI)
+	return changes
+}
+
+// replaceSites effectue le remplacement de applySites (statusMutex détenu en écriture), pour
+// les appelants qui doivent préparer la liste sous le même verrou
+func replaceSites(list []Site, fromAPI bool) siteChanges {
+	if fromAPI {
+		clear(pauseOverrides)
+	} else {
+		applyPauseOverrides(list)
+	}
+	previous := make(map[string]SiteStatus, len(statuses))
+	for _, st := range statuses {
+		previous[st.Site.ID] = st
+	}
+
+	now := time.Now()
+	newStatuses := make([]SiteStatus, len(list))
+	var added, changed, removed []string
+	kept := make(map[string]bool, len(list))
+	for i, s := range list {
+		kept[s.ID] = true
+		st, ok := previous[s.ID]
+		switch {
+		case !ok:
+			st = pendingStatus(s, now)
+			added = append(added, s.ID)
+		case !reflect.DeepEqual(st.Site, s):
+			st.Site = s
+			st = syncPauseState(st)
+			changed = append(changed, s.ID)
+		}
+		newStatuses[i] = st
+	}
+	for id := range previous {
+		if !kept[id] {
+			removed = append(removed, id)
+			delete(pauseOverrides, id)
+		}
+	}
+
+	sites = list
+	statuses = newStatuses
+	return siteChanges{added: added, changed: changed, removed: removed}
+}
+
+// finishReplaceSites complète un remplacement hors verrou : historique des sites supprimés,
+// transports inutilisés, replanification et journal
+func finishReplaceSites(list []Site, c siteChanges, fromAPI bool) {
+	pruneTransports(list)
+
+	for _, id := range c.removed {
+		forgetHistory(id)
+	}
+	for _, ids := range [][]string{c.added, c.changed, c.removed} {
+		for _, id := range ids {
+			rescheduleSite(id)
+		}
+	}
+	verb := "rechargée"
+	if fromAPI {
+		verb = "remplacée via l'API"
+	}
+	logInfo("🔄 Configuration %s : %d site(s), %d ajouté(s)%s, %d modifié(s)%s, %d supprimé(s)%s",
+		verb, len(list), len(c.added), idList(c.added), len(c.changed), idList(c.changed), len(c.removed), idList(c.removed))
+}
+
+// idList formate les IDs concernés par un rechargement, triés (vide s'il n'y en a aucun)
+func idList(ids []string) string {
+	if len(ids) == 0 {
+		return ""
+	}
+	sort.Strings(ids)
+	return " (" + strings.Join(ids, ", ") + ")"
+}
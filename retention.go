@@ -0,0 +1,179 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Rétention de l'historique : HISTORY_RETENTION_DAYS supprime les vérifications et les incidents
+// clos plus anciens, en base comme en mémoire ; HISTORY_MAX_ROWS borne à la fois le nombre de
+// vérifications de check_results et le nombre d'incidents clos (base et mémoire, chacun de son côté).
+// 0 (par défaut) désactive chaque règle. Le journal d'audit n'est jamais purgé.
+// Un site peut définir sa propre rétention (Site.HistoryRetention), appliquée à ses seules
+// vérifications à la place de HISTORY_RETENTION_DAYS ; HISTORY_MAX_ROWS reste une limite globale.
+var (
+	retentionPeriod time.Duration
+	historyMaxRows  int
+)
+
+// pruneInterval espace les purges ; la première a lieu au démarrage
+const pruneInterval = time.Hour
+
+// loadRetentionConfig lit HISTORY_RETENTION_DAYS et HISTORY_MAX_ROWS
+func loadRetentionConfig() {
+	retentionPeriod = time.Duration(envInt("HISTORY_RETENTION_DAYS", 0)) * 24 * time.Hour
+	historyMaxRows = envInt("HISTORY_MAX_ROWS", 0)
+	if retentionPeriod > 0 {
+		logInfo("🧹 Historique conservé %d jour(s)", int(retentionPeriod.Hours()/24))
+	}
+	if historyMaxRows > 0 && historyDB != nil {
+		logInfo("🧹 Base d'historique limitée à %d vérification(s)", historyMaxRows)
+	}
+}
+
+// runRetention purge l'historique à intervalle régulier jusqu'à l'annulation de ctx.
+// La boucle tourne même sans règle globale : un site ajouté plus tard peut définir la sienne.
+func runRetention(ctx context.Context) {
+	ticker := time.NewTicker(pruneInterval)
+	defer ticker.Stop()
+	for {
+		pruneHistory(time.Now())
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// siteRetention est la rétention propre à un site : au plus count vérifications,
+// ou celles de moins de age
+type siteRetention struct {
+	count int
+	age   time.Duration
+}
+
+// parseHistoryRetention lit un nombre de vérifications ("5000") ou une durée ("90m", "1h", "7d")
+func parseHistoryRetention(v string) (siteRetention, error) {
+	if v == "" {
+		return siteRetention{}, nil
+	}
+	if n, err := strconv.Atoi(v); err == nil {
+		if n <= 0 {
+			return siteRetention{}, fmt.Errorf("history_retention %q invalide (nombre de vérifications strictement positif attendu)", v)
+		}
+		return siteRetention{count: n}, nil
+	}
+	var d time.Duration
+	var err error
+	if days, ok := strings.CutSuffix(v, "d"); ok {
+		var n int
+		n, err = strconv.Atoi(days)
+		d = time.Duration(n) * 24 * time.Hour
+	} else {
+		d, err = time.ParseDuration(v)
+	}
+	if err != nil || d <= 0 {
+		return siteRetention{}, fmt.Errorf("history_retention %q invalide (nombre de vérifications ou durée, ex. 1000, 1h, 7d)", v)
+	}
+	return siteRetention{age: d}, nil
+}
+
+// historyRetentionOf renvoie la rétention propre au site (déjà validée avec la configuration)
+func historyRetentionOf(s Site) siteRetention {
+	r, _ := parseHistoryRetention(s.HistoryRetention)
+	return r
+}
+
+// pruneHistory applique la politique de rétention et journalise le nombre d'entrées supprimées
+func pruneHistory(now time.Time) {
+	// Coupures propres aux sites ; les autres suivent HISTORY_RETENTION_DAYS
+	cutoffs := make(map[string]time.Time)
+	var counts []Site
+	var overridden []any
+	for _, s := range currentSites() {
+		r := historyRetentionOf(s)
+		switch {
+		case r.age > 0:
+			cutoffs[s.ID] = now.Add(-r.age)
+		case r.count > 0:
+			// Le tampon en mémoire est déjà dimensionné à count
+			cutoffs[s.ID] = time.Time{}
+			counts = append(counts, s)
+		default:
+			continue
+		}
+		overridden = append(overridden, s.ID)
+	}
+	if retentionPeriod == 0 && historyMaxRows == 0 && len(overridden) == 0 {
+		return
+	}
+
+	var global time.Time
+	if retentionPeriod > 0 {
+		global = now.Add(-retentionPeriod)
+	}
+	memory := pruneMemoryHistory(cutoffs, global)
+	memoryIncidents := pruneClosedIncidents(global, historyMaxRows)
+
+	var rows, incidents int64
+	if historyDB != nil {
+		if !global.IsZero() {
+			query := `DELETE FROM check_results WHERE checked_at < ?`
+			if len(overridden) > 0 {
+				query += ` AND site_id NOT IN (?` + strings.Repeat(", ?", len(overridden)-1) + `)`
+			}
+			rows += execCount(query, append([]any{global.UnixMilli()}, overridden...)...)
+			incidents = execCount(`DELETE FROM incidents WHERE ended_at IS NOT NULL AND ended_at < ?`, global.UnixMilli())
+		}
+		for id, cutoff := range cutoffs {
+			if !cutoff.IsZero() {
+				rows += execCount(`DELETE FROM check_results WHERE site_id = ? AND checked_at < ?`, id, cutoff.UnixMilli())
+			}
+		}
+		for _, s := range counts {
+			rows += execCount(`DELETE FROM check_results WHERE rowid IN (
+				SELECT rowid FROM check_results WHERE site_id = ? ORDER BY checked_at DESC LIMIT -1 OFFSET ?)`, s.ID, historyRetentionOf(s).count)
+		}
+		if historyMaxRows > 0 {
+			rows += execCount(`DELETE FROM check_results WHERE rowid IN (
+				SELECT rowid FROM check_results ORDER BY checked_at DESC LIMIT -1 OFFSET ?)`, historyMaxRows)
+			incidents += execCount(`DELETE FROM incidents WHERE id IN (
+				SELECT id FROM incidents WHERE ended_at IS NOT NULL ORDER BY ended_at DESC LIMIT -1 OFFSET ?)`, historyMaxRows)
+		}
+	}
+	logInfo("🧹 Rétention : %d vérification(s) supprimée(s) de la base, %d en mémoire ; %d incident(s) clos de la base, %d en mémoire",
+		rows, memory, incidents, memoryIncidents)
+}
+
+// execCount exécute une suppression et renvoie le nombre de lignes concernées (0 en cas d'erreur)
+func execCount(query string, args ...any) int64 {
+	res, err := historyDB.Exec(query, args...)
+	if err != nil {
+		logWarn("⚠️ Purge de l'historique impossible : %v", err)
+		return 0
+	}
+	n, _ := res.RowsAffected()
+	return n
+}
+
+// pruneMemoryHistory retire des tampons en mémoire les vérifications antérieures à la coupure
+// du site (cutoffs) ou, à défaut, à global ; une coupure nulle ne retire rien
+func pruneMemoryHistory(cutoffs map[string]time.Time, global time.Time) int {
+	historyMutex.Lock()
+	defer historyMutex.Unlock()
+	pruned := 0
+	for id, buf := range history {
+		cutoff, ok := cutoffs[id]
+		if !ok {
+			cutoff = global
+		}
+		if !cutoff.IsZero() {
+			pruned += buf.dropBefore(cutoff)
+		}
+	}
+	return pruned
+}
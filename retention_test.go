@@ -0,0 +1,75 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseHistoryRetention(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    siteRetention
+		wantErr bool
+	}{
+		{"", siteRetention{}, false},
+		{"5000", siteRetention{count: 5000}, false},
+		{"1h", siteRetention{age: time.Hour}, false},
+		{"90m", siteRetention{age: 90 * time.Minute}, false},
+		{"7d", siteRetention{age: 7 * 24 * time.Hour}, false},
+		{"0", siteRetention{}, true},
+		{"-5", siteRetention{}, true},
+		{"0h", siteRetention{}, true},
+		{"d", siteRetention{}, true},
+		{"une semaine", siteRetention{}, true},
+	}
+	for _, tt := range tests {
+		got, err := parseHistoryRetention(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseHistoryRetention(%q) : erreur %v, attendue : %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("parseHistoryRetention(%q) = %+v, attendu %+v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestRingBufferResize(t *testing.T) {
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name  string
+		added int
+		size  int
+		want  []int // minutes des entrées conservées, de la plus ancienne à la plus récente
+	}{
+		{"agrandi", 3, 5, []int{0, 1, 2}},
+		{"réduit, entrées récentes gardées", 4, 2, []int{2, 3}},
+		{"réduit après rotation", 6, 3, []int{3, 4, 5}},
+		{"taille exacte", 4, 4, []int{0, 1, 2, 3}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := newRingBuffer(4)
+			for i := 0; i < tt.added; i++ {
+				b.add(checkRecord{At: base.Add(time.Duration(i) * time.Minute)})
+			}
+			b.resize(tt.size)
+			got := b.all()
+			if len(got) != len(tt.want) {
+				t.Fatalf("%d entrée(s), attendu %d", len(got), len(tt.want))
+			}
+			for i, m := range tt.want {
+				if !got[i].At.Equal(base.Add(time.Duration(m) * time.Minute)) {
+					t.Errorf("entrée %d : %s, attendu +%dmin", i, got[i].At, m)
+				}
+			}
+			// Le tampon redimensionné reste un anneau : un ajout évince la plus ancienne entrée une fois plein
+			for i := 0; i < tt.size; i++ {
+				b.add(checkRecord{At: base.Add(time.Hour)})
+			}
+			if n := len(b.all()); n != tt.size {
+				t.Errorf("après remplissage : %d entrée(s), attendu %d", n, tt.size)
+			}
+		})
+	}
+}
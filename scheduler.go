@@ -0,0 +1,307 @@
+package main
+
+import (
+	"context"
+	"math/rand/v2"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Intervalle global, utilisé par les sites sans interval_seconds.
+// Modifiable à chaud via POST /api/config/interval.
+var (
+	checkInterval  = 60 * time.Second
+	intervalMutex  sync.RWMutex
+	intervalChange = make(chan struct{}, 1)
+)
+
+// maxJitter décale aléatoirement chaque site dans sa fenêtre d'intervalle (CHECK_JITTER_SECONDS,
+// 0 par défaut : tous les sites partent ensemble) pour ne pas solliciter tous les upstreams au même instant
+var maxJitter time.Duration
+
+// Prochaine vérification planifiée de chaque site, publiée par la boucle à chaque réveil
+// pour /api/schedule (l'état de la boucle lui-même reste local à sa goroutine)
+var (
+	nextChecks    map[string]time.Time
+	scheduleMutex sync.RWMutex
+)
+
+// Sites ajoutés, modifiés ou supprimés via l'API depuis le dernier réveil de la boucle
+var (
+	rescheduled     = make(map[string]bool)
+	rescheduleMutex sync.Mutex
+	sitesChange     = make(chan struct{}, 1)
+)
+
+// loadIntervalConfig lit CHECK_INTERVAL_SECONDS (entier strictement positif, 60 par défaut)
+func loadIntervalConfig() {
+	seconds := 60
+	if v := os.Getenv("CHECK_INTERVAL_SECONDS"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			logWarn("⚠️ CHECK_INTERVAL_SECONDS invalide (%q), intervalle par défaut utilisé", v)
+		} else {
+			seconds = n
+		}
+	}
+	// Appelé avant le démarrage de la boucle : inutile de la prévenir
+	intervalMutex.Lock()
+	checkInterval = time.Duration(seconds) * time.Second
+	intervalMutex.Unlock()
+	logInfo("⏱️ Intervalle global de vérification : %s", currentInterval())
+
+	maxJitter = time.Duration(envInt("CHECK_JITTER_SECONDS", 0)) * time.Second
+	if maxJitter > 0 {
+		logInfo("🎲 Décalage aléatoire des vérifications : jusqu'à %s", maxJitter)
+	}
+}
+
+// startMonitoring lance une passe complète, immédiate ou après le délai initial, puis vérifie chaque site à sa propre cadence.
+// Les sites arrivant à échéance au même moment sont regroupés dans une même passe.
+// Avec un jitter, la première vérification de chaque site est décalée d'une durée aléatoire
+// inférieure à son intervalle ; la cadence conserve ensuite ce décalage, sans saut ni doublon.
+func startMonitoring(ctx context.Context) {
+	if !waitInitialDelay(ctx) {
+		logInfo("🛑 Monitoring arrêté avant la première passe (contexte annulé)")
+		return
+	}
+
+	// Première exécution immédiate (après INITIAL_DELAY_SECONDS éventuel)
+	if maxJitter == 0 && len(currentSites()) > 0 {
+		checkAllSites(ctx)
+	}
+
+	// Heure de la dernière vérification planifiée de chaque site
+	lastRun := make(map[string]time.Time)
+	now := time.Now()
+	for _, s := range currentSites() {
+		lastRun[s.ID] = now
+		if maxJitter > 0 {
+			interval := effectiveInterval(s)
+			lastRun[s.ID] = now.Add(siteJitter(interval) - interval)
+		}
+	}
+
+	timer := time.NewTimer(nextDelay(lastRun, siteIntervals(), now))
+	defer timer.Stop()
+	publishSchedule(lastRun, siteIntervals(), now)
+	idle := waitForSites(timer, false)
+
+	for {
+		select {
+		case <-ctx.Done():
+			logInfo("🛑 Monitoring arrêté (contexte annulé)")
+			return
+		case <-intervalChange:
+			// Les échéances sont recalculées ci-dessous avec le nouvel intervalle
+			logInfo("⏱️ Nouvel intervalle de vérification : %s", currentInterval())
+		case <-sitesChange:
+			// Sans échéance connue, un site est vérifié dès la prochaine passe
+			for _, id := range takeRescheduled() {
+				delete(lastRun, id)
+			}
+		case t := <-timer.C:
+			intervals := siteIntervals()
+			due := dueSites(lastRun, intervals, t)
+			if len(due) > 0 {
+				logInfo("🔍 Nouvelle passe de vérification à %s (%d site(s))\n", t.Format("2006-01-02 15:04:05"), len(due))
+				for _, s := range due {
+					lastRun[s.ID] = scheduledRun(lastRun[s.ID], intervals.of(s), t)
+				}
+				checkSites(ctx, due, true)
+			}
+		}
+		intervals, now := siteIntervals(), time.Now()
+		timer.Reset(nextDelay(lastRun, intervals, now))
+		publishSchedule(lastRun, intervals, now)
+		idle = waitForSites(timer, idle)
+	}
+}
+
+// waitForSites arrête le timer tant qu'aucun site n'est configuré : la boucle ne se réveille
+// plus que sur un ajout (API, rechargement) ou un changement d'intervalle. Renvoie le nouvel état.
+func waitForSites(timer *time.Timer, idle bool) bool {
+	if len(currentSites()) > 0 {
+		if idle {
+			logInfo("▶️ Premier site configuré, reprise du monitoring")
+		}
+		return false
+	}
+	timer.Stop()
+	if !idle {
+		logWarn("💤 Aucun site configuré (%s) : monitoring en attente d'un premier site", sitesConfigPath)
+	}
+	return true
+}
+
+// publishSchedule recopie les échéances de la boucle ; un site sans échéance connue
+// (nouveau ou replanifié) ou en retard sera vérifié au prochain réveil, soit maintenant
+func publishSchedule(lastRun map[string]time.Time, intervals intervalTable, now time.Time) {
+	next := make(map[string]time.Time)
+	for _, s := range activeSites(currentSites()) {
+		at := now
+		if last, ok := lastRun[s.ID]; ok && last.Add(intervals.of(s)).After(now) {
+			at = last.Add(intervals.of(s))
+		}
+		next[s.ID] = at
+	}
+	scheduleMutex.Lock()
+	nextChecks = next
+	scheduleMutex.Unlock()
+}
+
+// dueSites renvoie les sites dont l'échéance est atteinte à l'instant now
+func dueSites(lastRun map[string]time.Time, intervals intervalTable, now time.Time) []Site {
+	var due []Site
+	for _, s := range activeSites(currentSites()) {
+		if !now.Before(lastRun[s.ID].Add(intervals.of(s))) {
+			due = append(due, s)
+		}
+	}
+	return due
+}
+
+// scheduledRun renvoie l'heure théorique de la vérification en cours : on avance d'un intervalle
+// pour que la cadence ne dérive pas du temps de réveil, sauf après un retard de plus d'un intervalle.
+func scheduledRun(last time.Time, interval time.Duration, now time.Time) time.Time {
+	next := last.Add(interval)
+	if now.Sub(next) >= interval {
+		return now
+	}
+	return next
+}
+
+// siteJitter tire le décalage d'un site, borné par maxJitter et strictement inférieur à son intervalle
+func siteJitter(interval time.Duration) time.Duration {
+	window := min(maxJitter, interval)
+	if window <= 0 {
+		return 0
+	}
+	return rand.N(window)
+}
+
+// nextDelay renvoie le délai jusqu'à la prochaine échéance, tous sites confondus
+func nextDelay(lastRun map[string]time.Time, intervals intervalTable, now time.Time) time.Duration {
+	delay := currentInterval()
+	for _, s := range activeSites(currentSites()) {
+		if d := lastRun[s.ID].Add(intervals.of(s)).Sub(now); d < delay {
+			delay = d
+		}
+	}
+	return max(delay, 0)
+}
+
+// ScheduleEntry décrit la planification courante d'un site, tous modificateurs appliqués
+// (intervalle propre, backoff, décalage aléatoire, pause)
+type ScheduleEntry struct {
+	ID                       string     `json:"id"`
+	EffectiveIntervalSeconds int        `json:"effective_interval_seconds"`
+	NextCheckAt              *time.Time `json:"next_check_at"` // null pour un site en pause
+	Paused                   bool       `json:"paused"`
+	BackedOff                bool       `json:"backed_off"` // cadence ralentie après des échecs consécutifs
+}
+
+// handleSchedule renvoie la prochaine vérification planifiée de chaque site
+func handleSchedule(w http.ResponseWriter, r *http.Request) {
+	intervals := siteIntervals()
+	scheduleMutex.RLock()
+	next := nextChecks
+	scheduleMutex.RUnlock()
+
+	now := time.Now()
+	list := currentSites()
+	entries := make([]ScheduleEntry, len(list))
+	for i, s := range list {
+		interval := intervals.of(s)
+		e := ScheduleEntry{
+			ID:                       s.ID,
+			EffectiveIntervalSeconds: int(interval.Seconds()),
+			Paused:                   s.Paused,
+			BackedOff:                interval > effectiveInterval(s),
+		}
+		if !s.Paused {
+			// Pas encore publié (première passe en cours) : vérification imminente
+			at, ok := next[s.ID]
+			if !ok {
+				at = now
+			}
+			at = at.UTC()
+			e.NextCheckAt = &at
+		}
+		entries[i] = e
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	setStatusCacheHeaders(w)
+	writeJSON(w, r, entries)
+}
+
+// rescheduleSite demande à la boucle de vérifier un site dès la prochaine passe
+// (nouveau site ou configuration modifiée) ou d'oublier son échéance (site supprimé)
+func rescheduleSite(id string) {
+	rescheduleMutex.Lock()
+	rescheduled[id] = true
+	rescheduleMutex.Unlock()
+
+	select {
+	case sitesChange <- struct{}{}:
+	default:
+	}
+}
+
+// takeRescheduled renvoie et vide la liste des sites à replanifier
+func takeRescheduled() []string {
+	rescheduleMutex.Lock()
+	defer rescheduleMutex.Unlock()
+	ids := make([]string, 0, len(rescheduled))
+	for id := range rescheduled {
+		ids = append(ids, id)
+		delete(rescheduled, id)
+	}
+	return ids
+}
+
+// effectiveInterval renvoie la cadence propre au site, ou l'intervalle global par défaut
+func effectiveInterval(site Site) time.Duration {
+	if site.IntervalSeconds > 0 {
+		return time.Duration(site.IntervalSeconds) * time.Second
+	}
+	return currentInterval()
+}
+
+// logSiteIntervals journalise au démarrage la cadence retenue pour chaque site
+func logSiteIntervals() {
+	for _, s := range sites {
+		switch {
+		case s.IntervalSeconds < 0:
+			logEvent("warn", siteFields(s), "⚠️ %s : interval_seconds %d invalide, intervalle global (%s) utilisé", s.Name, s.IntervalSeconds, currentInterval())
+		case s.IntervalSeconds == 0:
+			logEvent("info", siteFields(s), "⏱️ %s : intervalle global (%s)", s.Name, currentInterval())
+		default:
+			logEvent("info", siteFields(s), "⏱️ %s : toutes les %s", s.Name, effectiveInterval(s))
+		}
+	}
+}
+
+// currentInterval renvoie l'intervalle global actif
+func currentInterval() time.Duration {
+	intervalMutex.RLock()
+	defer intervalMutex.RUnlock()
+	return checkInterval
+}
+
+// setInterval change l'intervalle global et prévient la boucle de monitoring
+func setInterval(d time.Duration) {
+	intervalMutex.Lock()
+	checkInterval = d
+	intervalMutex.Unlock()
+
+	// Envoi non bloquant : un signal déjà en attente suffit, la boucle relira la valeur
+	select {
+	case intervalChange <- struct{}{}:
+	default:
+	}
+}
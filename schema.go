@@ -0,0 +1,77 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+)
+
+// Schémas JSON compilés au chargement, indexés par chemin de fichier
+var (
+	schemas      = make(map[string]compiledSchema)
+	schemasMutex sync.RWMutex
+)
+
+// compiledSchema garde la date de modification du fichier compilé pour détecter ses mises à jour
+type compiledSchema struct {
+	schema  *jsonschema.Schema
+	modTime time.Time
+}
+
+// compileSchema compile le schéma JSON d'un site, à nouveau si le fichier a changé depuis :
+// un schéma modifié est pris en compte au prochain rechargement de la configuration
+func compileSchema(path string) error {
+	schemasMutex.Lock()
+	defer schemasMutex.Unlock()
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("schéma %s : %w", path, err)
+	}
+	if c, ok := schemas[path]; ok && c.modTime.Equal(info.ModTime()) {
+		return nil
+	}
+	sch, err := jsonschema.NewCompiler().Compile(path)
+	if err != nil {
+		return fmt.Errorf("schéma %s : %w", path, err)
+	}
+	schemas[path] = compiledSchema{schema: sch, modTime: info.ModTime()}
+	return nil
+}
+
+// checkSchema valide le corps contre le schéma du site et renvoie un message d'erreur si besoin
+func checkSchema(path string, data []byte, truncated bool) string {
+	schemasMutex.RLock()
+	sch := schemas[path].schema
+	schemasMutex.RUnlock()
+	if sch == nil {
+		return fmt.Sprintf("schéma %s non compilé", path)
+	}
+
+	if truncated {
+		return fmt.Sprintf("corps supérieur à %d octets, validation du schéma impossible", maxBodyRead)
+	}
+	inst, err := jsonschema.UnmarshalJSON(bytes.NewReader(data))
+	if err != nil {
+		return fmt.Sprintf("réponse non JSON : %v", err)
+	}
+
+	err = sch.Validate(inst)
+	if err == nil {
+		return ""
+	}
+	var ve *jsonschema.ValidationError
+	if !errors.As(err, &ve) {
+		return fmt.Sprintf("validation du schéma impossible : %v", err)
+	}
+	// On ne remonte que la première erreur feuille, plus lisible que l'arbre complet
+	for len(ve.Causes) > 0 {
+		ve = ve.Causes[0]
+	}
+	return "schéma non respecté : " + ve.Error()
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// shutdownTimeout borne l'arrêt propre : fin de la passe en cours, notifications, gRPC, traces,
+// requêtes HTTP (SHUTDOWN_TIMEOUT_SECONDS, 5 par défaut)
+var shutdownTimeout = 5 * time.Second
+
+// Vérifications en cours, par identifiant de site, pour savoir ce que l'arrêt interrompt
+var (
+	inFlightChecks = make(map[string]int)
+	inFlightMutex  sync.Mutex
+)
+
+// Notifications en cours d'envoi, par canal, attendues par l'arrêt propre pour que
+// la dernière alerte (un rétablissement juste avant un déploiement) parte quand même
+var (
+	notifyWG            sync.WaitGroup
+	inFlightNotify      = make(map[string]int)
+	inFlightNotifyMutex sync.Mutex
+)
+
+// loadShutdownConfig lit SHUTDOWN_TIMEOUT_SECONDS (entier strictement positif)
+func loadShutdownConfig() {
+	seconds := envInt("SHUTDOWN_TIMEOUT_SECONDS", 5)
+	if seconds == 0 {
+		logWarn("⚠️ SHUTDOWN_TIMEOUT_SECONDS doit être strictement positif, délai par défaut utilisé")
+		seconds = 5
+	}
+	shutdownTimeout = time.Duration(seconds) * time.Second
+}
+
+// beginCheck et endCheck encadrent une vérification en cours
+func beginCheck(id string) {
+	inFlightMutex.Lock()
+	inFlightChecks[id]++
+	inFlightMutex.Unlock()
+}
+
+func endCheck(id string) {
+	inFlightMutex.Lock()
+	if inFlightChecks[id]--; inFlightChecks[id] <= 0 {
+		delete(inFlightChecks, id)
+	}
+	inFlightMutex.Unlock()
+}
+
+// inFlightSites renvoie les identifiants des sites en cours de vérification, triés
+func inFlightSites() []string {
+	inFlightMutex.Lock()
+	defer inFlightMutex.Unlock()
+	ids := make([]string, 0, len(inFlightChecks))
+	for id := range inFlightChecks {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+// goNotify envoie une notification en arrière-plan en la comptant parmi les envois en cours
+func goNotify(channel string, send func()) {
+	notifyWG.Add(1)
+	inFlightNotifyMutex.Lock()
+	inFlightNotify[channel]++
+	inFlightNotifyMutex.Unlock()
+
+	go func() {
+		defer notifyWG.Done()
+		defer func() {
+			inFlightNotifyMutex.Lock()
+			if inFlightNotify[channel]--; inFlightNotify[channel] <= 0 {
+				delete(inFlightNotify, channel)
+			}
+			inFlightNotifyMutex.Unlock()
+		}()
+		send()
+	}()
+}
+
+// waitNotifications attend la fin des notifications en cours, au plus jusqu'à l'expiration de ctx.
+// Renvoie false si des envois restaient en cours.
+func waitNotifications(ctx context.Context) bool {
+	done := make(chan struct{})
+	go func() {
+		notifyWG.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// pendingNotifications décrit les notifications encore en cours, par canal (ex. "slack ×2")
+func pendingNotifications() string {
+	inFlightNotifyMutex.Lock()
+	defer inFlightNotifyMutex.Unlock()
+	channels := make([]string, 0, len(inFlightNotify))
+	for channel, n := range inFlightNotify {
+		channels = append(channels, fmt.Sprintf("%s ×%d", channel, n))
+	}
+	slices.Sort(channels)
+	return "notifications (" + strings.Join(channels, ", ") + ")"
+}
+
+// pendingPass décrit la passe interrompue par l'arrêt, avec les sites encore en vérification
+func pendingPass() string {
+	ids := inFlightSites()
+	if len(ids) == 0 {
+		return "passe de vérification"
+	}
+	return "passe de vérification (" + strings.Join(ids, ", ") + ")"
+}
+
+// logPendingShutdown journalise les opérations toujours en cours à l'expiration du délai d'arrêt
+func logPendingShutdown(pending []string) {
+	if len(pending) == 0 {
+		return
+	}
+	logWarn("⚠️ Délai d'arrêt de %s dépassé, opérations interrompues : %s", shutdownTimeout, strings.Join(pending, ", "))
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Gestion des sites à chaud via l'API. Les modifications ne sont pas écrites dans
+// config/sites.json : elles durent jusqu'au prochain redémarrage.
+
+// decodeSite lit et valide le corps JSON d'une requête de création ou de mise à jour
+func decodeSite(w http.ResponseWriter, r *http.Request) (Site, bool) {
+	var s Site
+	if err := json.NewDecoder(r.Body).Decode(&s); err != nil {
+		http.Error(w, "JSON invalide", http.StatusBadRequest)
+		return s, false
+	}
+	if s.Name == "" {
+		s.Name = s.ID
+	}
+	if problems := validateSite(s); len(problems) > 0 {
+		http.Error(w, strings.Join(problems, " ; "), http.StatusBadRequest)
+		return s, false
+	}
+	return s, true
+}
+
+// handleCreateSite ajoute un site ; il est vérifié dès la prochaine passe
+func handleCreateSite(w http.ResponseWriter, r *http.Request) {
+	s, ok := decodeSite(w, r)
+	if !ok {
+		return
+	}
+
+	statusMutex.Lock()
+	if _, exists := findSite(s.ID); exists {
+		statusMutex.Unlock()
+		http.Error(w, "Un site avec cet id existe déjà", http.StatusConflict)
+		return
+	}
+	// Nouveaux slices : les lecteurs qui détiennent les anciens ne sont pas affectés
+	delete(pauseOverrides, s.ID)
+	sites = append(append([]Site(nil), sites...), s)
+	statuses = append(append([]SiteStatus(nil), statuses...), pendingStatus(s, time.Now()))
+	statusMutex.Unlock()
+
+	forgetHistory(s.ID)
+	rescheduleSite(s.ID)
+	recordAudit(r, auditCreate, s.ID, "")
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	writeJSON(w, r, s)
+}
+
+// handleUpdateSite remplace la définition d'un site ; son statut repasse en attente
+func handleUpdateSite(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	s, ok := decodeSite(w, r)
+	if !ok {
+		return
+	}
+	if s.ID != id {
+		http.Error(w, "L'id du corps ne correspond pas à l'URL", http.StatusBadRequest)
+		return
+	}
+
+	statusMutex.Lock()
+	newSites := append([]Site(nil), sites...)
+	found := false
+	for i := range newSites {
+		if newSites[i].ID == id {
+			s = keepRedactedSecrets(s, newSites[i], true)
+			newSites[i] = s
+			found = true
+		}
+	}
+	if !found {
+		statusMutex.Unlock()
+		http.Error(w, "Site introuvable", http.StatusNotFound)
+		return
+	}
+	newStatuses := append([]SiteStatus(nil), statuses...)
+	for i := range newStatuses {
+		if newStatuses[i].Site.ID == id {
+			newStatuses[i] = pendingStatus(s, time.Now())
+		}
+	}
+	// La définition complète fait foi, y compris paused
+	delete(pauseOverrides, id)
+	sites, statuses = newSites, newStatuses
+	statusMutex.Unlock()
+
+	pruneTransports(newSites)
+	rescheduleSite(id)
+	recordAudit(r, auditUpdate, id, "")
+
+	w.Header().Set("Content-Type", "application/json")
+	writeJSON(w, r, s)
+}
+
+// handleDeleteSite arrête la surveillance d'un site et oublie son historique en mémoire
+func handleDeleteSite(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	statusMutex.Lock()
+	if _, exists := findSite(id); !exists {
+		statusMutex.Unlock()
+		http.Error(w, "Site introuvable", http.StatusNotFound)
+		return
+	}
+	newSites := make([]Site, 0, len(sites))
+	for _, s := range sites {
+		if s.ID != id {
+			newSites = append(newSites, s)
+		}
+	}
+	newStatuses := make([]SiteStatus, 0, len(statuses))
+	for _, st := range statuses {
+		if st.Site.ID != id {
+			newStatuses = append(newStatuses, st)
+		}
+	}
+	delete(pauseOverrides, id)
+	sites, statuses = newSites, newStatuses
+	statusMutex.Unlock()
+
+	pruneTransports(newSites)
+	forgetHistory(id)
+	rescheduleSite(id)
+	recordAudit(r, auditDelete, id, "")
+	w.WriteHeader(http.StatusNoContent)
+}
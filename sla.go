@@ -0,0 +1,64 @@
+package main
+
+import (
+	"math"
+	"net/http"
+	"sort"
+)
+
+// SLAReport mesure le respect du temps de réponse garanti d'un site sur l'historique conservé.
+// Une vérification en échec ne respecte pas le SLA, quelle que soit sa durée.
+type SLAReport struct {
+	ID             string   `json:"id"`
+	SLAResponseMs  int64    `json:"sla_response_ms"`
+	ChecksRecorded int      `json:"checks_recorded"`
+	MetCount       int      `json:"met_count"`
+	SLAMetPct      *float64 `json:"sla_met_pct"` // null tant qu'aucune vérification n'est enregistrée
+	Breaching      bool     `json:"breaching"`   // la dernière vérification ne respecte pas le SLA
+}
+
+// SLASummary regroupe les rapports, les sites en infraction étant listés à part et en tête
+type SLASummary struct {
+	Breaching []string    `json:"breaching"`
+	Sites     []SLAReport `json:"sites"`
+}
+
+// computeSLAReport calcule le rapport SLA d'un site à partir de son historique
+func computeSLAReport(id string, slaMs int64, records []checkRecord) SLAReport {
+	rep := SLAReport{ID: id, SLAResponseMs: slaMs, ChecksRecorded: len(records)}
+	met := func(rec checkRecord) bool { return rec.IsUp && rec.ResponseTime <= slaMs }
+	for _, rec := range records {
+		if met(rec) {
+			rep.MetCount++
+		}
+	}
+	if len(records) > 0 {
+		pct := math.Round(float64(rep.MetCount)/float64(len(records))*10000) / 100
+		rep.SLAMetPct = &pct
+		rep.Breaching = !met(records[len(records)-1])
+	}
+	return rep
+}
+
+// handleSLA renvoie le respect du SLA de chaque site qui en définit un
+func handleSLA(w http.ResponseWriter, r *http.Request) {
+	summary := SLASummary{Breaching: []string{}, Sites: []SLAReport{}}
+	for _, s := range currentSites() {
+		if s.SLAResponseMs <= 0 {
+			continue
+		}
+		rep := computeSLAReport(s.ID, s.SLAResponseMs, siteHistory(s.ID))
+		if rep.Breaching {
+			summary.Breaching = append(summary.Breaching, s.ID)
+		}
+		summary.Sites = append(summary.Sites, rep)
+	}
+	// Infractions en tête, ordre de la configuration ensuite
+	sort.SliceStable(summary.Sites, func(i, j int) bool {
+		return summary.Sites[i].Breaching && !summary.Sites[j].Breaching
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	setStatusCacheHeaders(w)
+	writeJSON(w, r, summary)
+}
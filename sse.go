@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// streamsCtx est annulé à l'arrêt du serveur HTTP pour fermer les flux SSE ouverts,
+// qui empêcheraient sinon srv.Shutdown de se terminer
+var streamsCtx, stopStreams = context.WithCancel(context.Background())
+
+// sseKeepAlive espace les commentaires envoyés pour garder la connexion ouverte derrière un proxy
+const sseKeepAlive = 30 * time.Second
+
+// handleStream pousse les statuts en Server-Sent Events : un événement "snapshot" avec la liste
+// complète à la connexion, puis un événement "update" avec les seuls sites revérifiés à chaque passe
+func handleStream(w http.ResponseWriter, r *http.Request) {
+	rc := http.NewResponseController(w)
+	// Le WriteTimeout du serveur couperait le flux au bout de quelques secondes
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil {
+		http.Error(w, "Streaming non supporté", http.StatusInternalServerError)
+		return
+	}
+
+	updates, unsubscribe := subscribeStatuses()
+	defer unsubscribe()
+
+	statusMutex.RLock()
+	current := statuses
+	statusMutex.RUnlock()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Connection", "keep-alive")
+
+	if err := writeEvent(w, rc, "snapshot", current); err != nil {
+		return
+	}
+	lastChecked := make(map[string]time.Time, len(current))
+	for _, st := range current {
+		lastChecked[st.Site.ID] = st.LastChecked
+	}
+
+	keepAlive := time.NewTicker(sseKeepAlive)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-streamsCtx.Done():
+			return
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+			if err := rc.Flush(); err != nil {
+				return
+			}
+		case snapshot := <-updates:
+			var changed []SiteStatus
+			for _, st := range snapshot {
+				if !st.LastChecked.Equal(lastChecked[st.Site.ID]) {
+					changed = append(changed, st)
+					lastChecked[st.Site.ID] = st.LastChecked
+				}
+			}
+			if len(changed) == 0 {
+				continue
+			}
+			if err := writeEvent(w, rc, "update", changed); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// writeEvent écrit un événement SSE dont la donnée est encodée en JSON sur une seule ligne
+func writeEvent(w http.ResponseWriter, rc *http.ResponseController, event string, data any) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, payload); err != nil {
+		return err
+	}
+	return rc.Flush()
+}
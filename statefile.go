@@ -0,0 +1,77 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+)
+
+// stateFile est le fichier où sont conservés les derniers statuts entre deux redémarrages
+// (désactivé si STATE_FILE n'est pas défini)
+var stateFile = os.Getenv("STATE_FILE")
+
+// restoreStatuses recharge les derniers statuts connus depuis STATE_FILE.
+// Seuls les sites encore configurés sont restaurés, avec leur configuration actuelle.
+func restoreStatuses() {
+	if stateFile == "" {
+		return
+	}
+
+	data, err := os.ReadFile(stateFile)
+	if errors.Is(err, fs.ErrNotExist) {
+		return
+	}
+	if err != nil {
+		logWarn("⚠️ Statuts précédents non restaurés : %v", err)
+		return
+	}
+	var saved []SiteStatus
+	if err := json.Unmarshal(data, &saved); err != nil {
+		logWarn("⚠️ Fichier d'état %s illisible, ignoré : %v", stateFile, err)
+		return
+	}
+
+	previous := make(map[string]SiteStatus, len(saved))
+	for _, st := range saved {
+		previous[st.Site.ID] = st
+	}
+
+	statusMutex.Lock()
+	defer statusMutex.Unlock()
+	restored := 0
+	for i, st := range statuses {
+		if prev, ok := previous[st.Site.ID]; ok {
+			prev.Site = st.Site
+			statuses[i] = syncPauseState(prev)
+			restored++
+		}
+	}
+	logInfo("♻️ %d statut(s) restauré(s) depuis %s", restored, stateFile)
+}
+
+// saveStatuses écrit les statuts courants dans STATE_FILE (écriture atomique via un fichier temporaire)
+func saveStatuses() {
+	if stateFile == "" {
+		return
+	}
+
+	statusMutex.RLock()
+	data, err := json.Marshal(statuses)
+	statusMutex.RUnlock()
+	if err != nil {
+		logWarn("⚠️ Statuts non sauvegardés : %v", err)
+		return
+	}
+
+	tmp := stateFile + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		logWarn("⚠️ Statuts non sauvegardés : %v", err)
+		return
+	}
+	if err := os.Rename(tmp, stateFile); err != nil {
+		logWarn("⚠️ Statuts non sauvegardés : %v", err)
+		return
+	}
+	logInfo("💾 Statuts sauvegardés dans %s", stateFile)
+}
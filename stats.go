@@ -0,0 +1,71 @@
+package main
+
+import (
+	"math"
+	"net/http"
+	"slices"
+)
+
+// LatencyStats résume les temps de réponse (ms) d'un site sur l'historique conservé.
+// Les valeurs sont null tant qu'aucune vérification n'est enregistrée.
+type LatencyStats struct {
+	Samples int      `json:"samples"`
+	Min     *int64   `json:"min_ms"`
+	Max     *int64   `json:"max_ms"`
+	Mean    *float64 `json:"mean_ms"`
+	P50     *int64   `json:"p50_ms"`
+	P90     *int64   `json:"p90_ms"`
+	P99     *int64   `json:"p99_ms"`
+}
+
+// computeLatencyStats calcule les statistiques d'une série de vérifications
+func computeLatencyStats(records []checkRecord) LatencyStats {
+	stats := LatencyStats{Samples: len(records)}
+	if len(records) == 0 {
+		return stats
+	}
+
+	times := make([]int64, len(records))
+	var sum int64
+	for i, rec := range records {
+		times[i] = rec.ResponseTime
+		sum += rec.ResponseTime
+	}
+	slices.Sort(times)
+
+	mean := math.Round(float64(sum)/float64(len(times))*100) / 100
+	stats.Min = &times[0]
+	stats.Max = &times[len(times)-1]
+	stats.Mean = &mean
+	stats.P50 = percentile(times, 50)
+	stats.P90 = percentile(times, 90)
+	stats.P99 = percentile(times, 99)
+	return stats
+}
+
+// percentile applique la méthode du rang le plus proche sur une série triée non vide :
+// avec peu d'échantillons, le résultat est simplement la valeur la plus haute disponible
+func percentile(sorted []int64, p int) *int64 {
+	rank := int(math.Ceil(float64(p) / 100 * float64(len(sorted))))
+	v := sorted[max(rank-1, 0)]
+	return &v
+}
+
+// handleStats renvoie les statistiques de latence de chaque site, indexées par id
+func handleStats(w http.ResponseWriter, r *http.Request) {
+	statusMutex.RLock()
+	ids := make([]string, len(sites))
+	for i, s := range sites {
+		ids[i] = s.ID
+	}
+	statusMutex.RUnlock()
+
+	stats := make(map[string]LatencyStats, len(ids))
+	for _, id := range ids {
+		stats[id] = computeLatencyStats(siteHistory(id))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	setStatusCacheHeaders(w)
+	writeJSON(w, r, stats)
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"time"
+)
+
+// tcpTarget renvoie l'adresse host:port d'un site de type tcp (préfixe tcp:// facultatif)
+func tcpTarget(site Site) string {
+	return strings.TrimPrefix(site.URL, "tcp://")
+}
+
+// validateTCPTarget vérifie qu'un site tcp désigne bien une adresse host:port
+func validateTCPTarget(site Site) error {
+	host, port, err := net.SplitHostPort(tcpTarget(site))
+	if err != nil || host == "" || port == "" {
+		return fmt.Errorf("cible %q invalide (host:port attendu)", site.URL)
+	}
+	if needsBody(site) {
+		return fmt.Errorf("les assertions sur le corps ne s'appliquent pas aux sites tcp")
+	}
+	return nil
+}
+
+// checkTCP vérifie qu'une connexion TCP aboutit ; la durée mesurée est celle de la connexion
+func checkTCP(site Site) SiteStatus {
+	dialer := &net.Dialer{Timeout: siteTimeout(site)}
+	if site.SourceIP != "" {
+		dialer.LocalAddr = &net.TCPAddr{IP: net.ParseIP(site.SourceIP)}
+	}
+
+	start := time.Now()
+	conn, err := dialer.Dial(siteNetwork(site), tcpTarget(site))
+	elapsed := time.Since(start)
+
+	status := SiteStatus{
+		Site:             site,
+		ResponseTime:     elapsed.Milliseconds(),
+		ResponseTimeUs:   elapsed.Microseconds(),
+		RequestStartedAt: start,
+	}
+	if err != nil {
+		status.Error = err.Error()
+		if family := dialFamily(err); family != "" {
+			status.Error += " (" + family + ")"
+		}
+		status.ErrorType = classifyError(err)
+		status.State = stateDown
+	} else {
+		status.IsUp = true
+		status.State = stateUp
+		status.RemoteAddr = conn.RemoteAddr().String()
+		if site.SourceIP != "" {
+			status.SourceAddr = conn.LocalAddr().String()
+		}
+		conn.Close()
+	}
+	status.LastChecked = time.Now()
+	return status
+}
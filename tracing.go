@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracer produit une span par passe et une span par site. Tant qu'aucun exportateur
+// n'est configuré, le fournisseur global d'OpenTelemetry est un no-op sans coût notable.
+var tracer = otel.Tracer("site-monitor")
+
+// initTracing active l'export OTLP/HTTP si OTEL_EXPORTER_OTLP_ENDPOINT ou
+// OTEL_EXPORTER_OTLP_TRACES_ENDPOINT est défini (variables standard, lues par l'exportateur,
+// comme OTEL_EXPORTER_OTLP_HEADERS ou OTEL_SERVICE_NAME). Renvoie la fonction qui vide
+// les spans en attente à l'arrêt.
+func initTracing(ctx context.Context) func(context.Context) {
+	if os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") == "" && os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") == "" {
+		return func(context.Context) {}
+	}
+	if os.Getenv("OTEL_TRACES_EXPORTER") == "none" {
+		return func(context.Context) {}
+	}
+
+	exporter, err := otlptracehttp.New(ctx)
+	if err != nil {
+		logWarn("⚠️ Export OpenTelemetry désactivé : %v", err)
+		return func(context.Context) {}
+	}
+	// Les détecteurs suivants priment : OTEL_SERVICE_NAME et OTEL_RESOURCE_ATTRIBUTES
+	// remplacent le nom par défaut
+	res, err := resource.New(ctx,
+		resource.WithAttributes(semconv.ServiceName("site-monitor")),
+		resource.WithTelemetrySDK(),
+		resource.WithFromEnv(),
+	)
+	if err != nil {
+		res = resource.Default()
+	}
+	tp := sdktrace.NewTracerProvider(sdktrace.WithBatcher(exporter), sdktrace.WithResource(res))
+	otel.SetTracerProvider(tp)
+	logInfo("🔭 Traces OpenTelemetry exportées en OTLP/HTTP")
+
+	return func(ctx context.Context) {
+		if err := tp.Shutdown(ctx); err != nil {
+			logWarn("⚠️ Vidage des traces OpenTelemetry incomplet : %v", err)
+		}
+	}
+}
+
+// startCheckSpan ouvre la span d'une vérification, fille de la span de passe portée par ctx
+func startCheckSpan(ctx context.Context, site Site) (context.Context, trace.Span) {
+	return tracer.Start(ctx, "check_site", trace.WithAttributes(
+		attribute.String("site.id", site.ID),
+		attribute.String("site.name", site.Name),
+		attribute.String("url.full", redactURLPassword(site.URL)),
+		attribute.String("check.type", checkType(site)),
+	))
+}
+
+// endCheckSpan enregistre le résultat d'une vérification et marque la span en erreur si le site est en panne
+func endCheckSpan(span trace.Span, st SiteStatus) {
+	span.SetAttributes(
+		attribute.String("check.state", st.State),
+		attribute.Int("http.response.status_code", st.StatusCode),
+		attribute.Int64("check.response_time_ms", st.ResponseTime),
+	)
+	if !st.IsUp {
+		span.SetStatus(codes.Error, st.Error)
+	}
+	span.End()
+}
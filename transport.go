@@ -0,0 +1,194 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Paramètres du transport HTTP partagé par toutes les vérifications
+var (
+	idleConnTimeout     time.Duration
+	maxIdleConnsPerHost int
+	tcpKeepAlive        time.Duration
+
+	// userAgent est envoyé par défaut à chaque vérification : certains anti-bots
+	// rejettent le Go-http-client par défaut
+	userAgent = defaultUserAgent
+
+	sharedTransport *http.Transport
+
+	// Transports dédiés aux sites ayant des options réseau spécifiques,
+	// indexés par la combinaison de ces options
+	siteTransports      = make(map[string]*http.Transport)
+	siteTransportsMutex sync.Mutex
+)
+
+const defaultUserAgent = "site-monitor/1.0"
+
+// initTransport construit le transport partagé à partir de l'environnement
+func initTransport() {
+	idleConnTimeout = time.Duration(envInt("HTTP_IDLE_CONN_TIMEOUT_SECONDS", 90)) * time.Second
+	maxIdleConnsPerHost = envInt("HTTP_MAX_IDLE_CONNS_PER_HOST", 2)
+	tcpKeepAlive = time.Duration(envInt("HTTP_TCP_KEEPALIVE_SECONDS", 30)) * time.Second
+	userAgent = envString("USER_AGENT", defaultUserAgent)
+
+	sharedTransport = http.DefaultTransport.(*http.Transport).Clone()
+	sharedTransport.DialContext = newDialer().DialContext
+	sharedTransport.IdleConnTimeout = idleConnTimeout
+	sharedTransport.MaxIdleConnsPerHost = maxIdleConnsPerHost
+
+	logInfo("🔌 Transport HTTP : idle timeout %s, %d connexion(s) inactive(s) par hôte, keep-alive TCP %s, User-Agent %q",
+		idleConnTimeout, maxIdleConnsPerHost, tcpKeepAlive, userAgent)
+}
+
+// newDialer renvoie un dialer utilisant les réglages keep-alive globaux
+func newDialer() *net.Dialer {
+	return &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: tcpKeepAlive,
+	}
+}
+
+// transportKey résume les options réseau d'un site ; une clé vide désigne le transport partagé
+func transportKey(site Site) string {
+	var parts []string
+	if site.IdleTimeoutSeconds > 0 {
+		parts = append(parts, fmt.Sprintf("idle=%d", site.IdleTimeoutSeconds))
+	}
+	if site.ForceHTTP1 {
+		parts = append(parts, "h1")
+	}
+	if site.DisableKeepAlive {
+		parts = append(parts, "nokeepalive")
+	}
+	if site.SourceIP != "" {
+		parts = append(parts, "src="+site.SourceIP)
+	}
+	if network := siteNetwork(site); network != "tcp" {
+		parts = append(parts, "net="+network)
+	}
+	if site.Proxy != "" {
+		parts = append(parts, "proxy="+site.Proxy)
+	}
+	return strings.Join(parts, ",")
+}
+
+// transportFor renvoie le transport à utiliser pour vérifier un site.
+// Les sites partageant les mêmes options réutilisent le même transport (et donc ses connexions).
+func transportFor(site Site) *http.Transport {
+	key := transportKey(site)
+	if key == "" {
+		return sharedTransport
+	}
+
+	siteTransportsMutex.Lock()
+	defer siteTransportsMutex.Unlock()
+
+	if t, ok := siteTransports[key]; ok {
+		return t
+	}
+	t := sharedTransport.Clone()
+	if site.IdleTimeoutSeconds > 0 {
+		t.IdleConnTimeout = time.Duration(site.IdleTimeoutSeconds) * time.Second
+	}
+	if site.ForceHTTP1 {
+		// Une map TLSNextProto non nil et vide désactive HTTP/2 côté client
+		t.ForceAttemptHTTP2 = false
+		t.TLSNextProto = make(map[string]func(string, *tls.Conn) http.RoundTripper)
+		if t.TLSClientConfig == nil {
+			t.TLSClientConfig = &tls.Config{}
+		}
+		t.TLSClientConfig.NextProtos = []string{"http/1.1"}
+	}
+	if site.DisableKeepAlive {
+		t.DisableKeepAlives = true
+	}
+	if network := siteNetwork(site); site.SourceIP != "" || network != "tcp" {
+		d := newDialer()
+		if site.SourceIP != "" {
+			d.LocalAddr = &net.TCPAddr{IP: net.ParseIP(site.SourceIP)}
+		}
+		// Le transport demande toujours "tcp" : la famille imposée par le site la remplace
+		t.DialContext = func(ctx context.Context, _, addr string) (net.Conn, error) {
+			return d.DialContext(ctx, network, addr)
+		}
+	}
+	if site.Proxy != "" {
+		// Déjà validée avec la configuration
+		if u, err := parseProxyURL(site.Proxy); err == nil {
+			t.Proxy = http.ProxyURL(u)
+		}
+	}
+	siteTransports[key] = t
+	return t
+}
+
+// pruneTransports retire les transports dédiés qu'aucun site de list n'utilise plus (site supprimé,
+// options réseau modifiées) et ferme leurs connexions inactives
+func pruneTransports(list []Site) {
+	used := make(map[string]bool, len(list))
+	for _, s := range list {
+		used[transportKey(s)] = true
+	}
+
+	siteTransportsMutex.Lock()
+	defer siteTransportsMutex.Unlock()
+	for key, t := range siteTransports {
+		if !used[key] {
+			t.CloseIdleConnections()
+			delete(siteTransports, key)
+		}
+	}
+}
+
+// siteNetwork renvoie le réseau à composer pour un site : tcp4, tcp6 ou tcp (les deux familles)
+func siteNetwork(site Site) string {
+	if site.Network == "" {
+		return "tcp"
+	}
+	return site.Network
+}
+
+// dialFamily indique la famille d'adresses (IPv4 ou IPv6) d'une connexion en échec,
+// vide si l'erreur ne vient pas de l'établissement de la connexion
+func dialFamily(err error) string {
+	var opErr *net.OpError
+	if !errors.As(err, &opErr) || opErr.Op != "dial" {
+		return ""
+	}
+	if addr, ok := opErr.Addr.(*net.TCPAddr); ok {
+		if addr.IP.To4() != nil {
+			return "IPv4"
+		}
+		return "IPv6"
+	}
+	// Aucune adresse tentée (famille imposée sans enregistrement DNS correspondant)
+	switch opErr.Net {
+	case "tcp4":
+		return "IPv4"
+	case "tcp6":
+		return "IPv6"
+	}
+	return ""
+}
+
+// checkSourceIP vérifie qu'une adresse source est valide et attribuée à une interface locale
+func checkSourceIP(ip string) error {
+	if net.ParseIP(ip) == nil {
+		return fmt.Errorf("adresse source invalide %q", ip)
+	}
+	// Se lier à un port éphémère échoue si l'adresse n'est pas locale
+	conn, err := net.ListenPacket("udp", net.JoinHostPort(ip, "0"))
+	if err != nil {
+		return fmt.Errorf("adresse source %s non attribuable : %w", ip, err)
+	}
+	conn.Close()
+	return nil
+}
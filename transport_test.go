@@ -0,0 +1,42 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestPruneTransports(t *testing.T) {
+	old, oldShared := siteTransports, sharedTransport
+	defer func() { siteTransports, sharedTransport = old, oldShared }()
+	sharedTransport = &http.Transport{}
+
+	h1 := Site{ID: "h1", ForceHTTP1: true}
+	proxied := Site{ID: "proxied", Proxy: "http://proxy:3128"}
+	tests := []struct {
+		name  string
+		sites []Site
+		want  []Site // sites dont le transport doit rester en cache
+	}{
+		{"tous conservés", []Site{h1, proxied}, []Site{h1, proxied}},
+		{"site supprimé", []Site{h1}, []Site{h1}},
+		{"options modifiées", []Site{{ID: "h1", DisableKeepAlive: true}, proxied}, []Site{proxied}},
+		{"plus aucun site", nil, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			siteTransports = make(map[string]*http.Transport)
+			transportFor(h1)
+			transportFor(proxied)
+
+			pruneTransports(tt.sites)
+			if len(siteTransports) != len(tt.want) {
+				t.Errorf("%d transport(s) en cache, attendu %d", len(siteTransports), len(tt.want))
+			}
+			for _, s := range tt.want {
+				if _, ok := siteTransports[transportKey(s)]; !ok {
+					t.Errorf("transport de %s retiré", s.ID)
+				}
+			}
+		})
+	}
+}
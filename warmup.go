@@ -0,0 +1,44 @@
+package main
+
+import (
+	"context"
+	"time"
+)
+
+// Démarrage en douceur, pour les déploiements progressifs : la première passe peut attendre
+// INITIAL_DELAY_SECONDS, et pendant WARMUP_SECONDS après le lancement les échecs sont
+// journalisés sans déclencher d'alerte. Les deux valent 0 par défaut.
+var (
+	initialDelay time.Duration
+	warmupPeriod time.Duration
+)
+
+// loadWarmupConfig lit INITIAL_DELAY_SECONDS et WARMUP_SECONDS
+func loadWarmupConfig() {
+	initialDelay = time.Duration(envInt("INITIAL_DELAY_SECONDS", 0)) * time.Second
+	warmupPeriod = time.Duration(envInt("WARMUP_SECONDS", 0)) * time.Second
+	if initialDelay > 0 {
+		logInfo("⏳ Première passe de vérification différée de %s", initialDelay)
+	}
+	if warmupPeriod > 0 {
+		logInfo("🌡️ Période de démarrage : pas d'alerte pendant les %s suivant le lancement", warmupPeriod)
+	}
+}
+
+// inWarmup indique si t tombe dans la période de démarrage
+func inWarmup(t time.Time) bool {
+	return warmupPeriod > 0 && t.Before(startTime.Add(warmupPeriod))
+}
+
+// waitInitialDelay attend le délai de première passe ; renvoie false si ctx est annulé entre-temps
+func waitInitialDelay(ctx context.Context) bool {
+	if initialDelay == 0 {
+		return true
+	}
+	select {
+	case <-ctx.Done():
+		return false
+	case <-time.After(initialDelay):
+		return true
+	}
+}
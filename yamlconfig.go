@@ -0,0 +1,59 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// sitesConfigCandidates sont les fichiers cherchés, dans l'ordre, quand SITES_CONFIG n'est pas défini
+var sitesConfigCandidates = []string{"config/sites.json", "config/sites.yaml", "config/sites.yml"}
+
+// resolveSitesConfigPath renvoie SITES_CONFIG s'il est défini, sinon le premier fichier existant
+// parmi les candidats (config/sites.json par défaut)
+func resolveSitesConfigPath() string {
+	if p := os.Getenv("SITES_CONFIG"); p != "" {
+		return p
+	}
+	for _, p := range sitesConfigCandidates {
+		if _, err := os.Stat(p); err == nil {
+			return p
+		}
+	}
+	return sitesConfigCandidates[0]
+}
+
+// isYAMLPath indique si le fichier doit être lu comme du YAML (extension .yaml ou .yml)
+func isYAMLPath(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return true
+	}
+	return false
+}
+
+// decodeYAMLSiteObjects décode une liste de sites YAML dans la même représentation que le JSON,
+// pour que fusion d'overlay et validation restent identiques quel que soit le format.
+// Les erreurs de yaml.v3 indiquent la ligne fautive.
+func decodeYAMLSiteObjects(path string, data []byte) ([]map[string]json.RawMessage, error) {
+	var docs []map[string]any
+	if err := yaml.Unmarshal(data, &docs); err != nil {
+		return nil, fmt.Errorf("%s : %w", path, err)
+	}
+	objs := make([]map[string]json.RawMessage, len(docs))
+	for i, doc := range docs {
+		objs[i] = make(map[string]json.RawMessage, len(doc))
+		for k, v := range doc {
+			raw, err := json.Marshal(v)
+			if err != nil {
+				return nil, fmt.Errorf("%s : site #%d, champ %q : %w", path, i+1, k, err)
+			}
+			objs[i][k] = raw
+		}
+	}
+	return objs, nil
+}